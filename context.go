@@ -1,6 +1,17 @@
 package runner
 
-import "sync"
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrCanceled is set on the Context by Commands that stop executing early because the Context's Done channel was
+// closed.
+var ErrCanceled = errors.New("command canceled")
 
 // A Context encapsulates the state for a Command to execute with. Contexts are passed down to subsequent Commands,
 // allowing them to access data from previous Commands. Setting a non-nil error on the Context will initiate a rollback
@@ -23,25 +34,92 @@ type Context interface {
 	// original values are accessible during a rollback or within parallel Commands.
 	Set(key, val interface{})
 
+	// Provenance returns the record of which Command set the value currently returned by Get for key, as well as whether
+	// or not a value was found for that key.
+	Provenance(key interface{}) (prov Provenance, found bool)
+
+	// Done returns a channel that is closed when the Command should stop executing early, such as when a competing
+	// Command has already won a Race. Long-running Commands should select on this channel and set ErrCanceled on
+	// the Context if they abort; Commands that complete successfully after cancellation may be rolled back.
+	Done() <-chan struct{}
+
 	push()
 	pop()
 	unsetErr()
+	cancel()
+	onCancel(fn func()) (remove func())
+	locals() hash
+	layers() []layer
+	locks() *lockRegistry
+	enter(cmd interface{})
+	leave()
+	path() []string
+	trace() []interface{}
+	self() Context
+	observe(o Observer)
+	notify(e Event)
+	attempt(cmd interface{}, path string) int
+	attempted(cmd interface{}, path string, failed bool)
 }
 
+// Provenance records which Command set a value on a Context, and when.
+type Provenance struct {
+	// Command is the path of the Command that set the value, from the outermost Command inward. It is empty if the value
+	// was set outside of a Command, such as on a Context returned by NewContext.
+	Command string
+
+	// Time is when the value was set.
+	Time time.Time
+}
+
+func (p Provenance) String() string {
+	cmd := p.Command
+	if cmd == "" {
+		cmd = "<none>"
+	}
+	return fmt.Sprintf("%s at %s", cmd, p.Time.Format(time.RFC3339Nano))
+}
+
+// commandPathSep separates the Commands in Provenance.Command.
+const commandPathSep = " > "
+
 // NewContext returns a new root context. This function is a utility to aid in testing Command implementations.
 func NewContext() Context {
 	return &ctx{
-		kvs: []hash{make(hash)},
+		store:   newStore(),
+		done:    make(chan struct{}),
+		lockReg: newLockRegistry(),
 	}
 }
 
 type hash map[interface{}]interface{}
 
+type provs map[interface{}]Provenance
+
+// layer is a single level of a Context's key-value stack, along with the number of forks (sub-contexts) between it and
+// the root Context.
+type layer struct {
+	kvs  hash
+	prov provs
+	fork int
+}
+
 type ctx struct {
 	sync.RWMutex
 
-	kvs []hash
-	err error
+	store store
+	err   error
+
+	cmds      []string
+	stack     []interface{}
+	observers []Observer
+	attempts  map[interface{}]int
+
+	done      chan struct{}
+	onCancels map[int]func()
+	cancelID  int
+
+	lockReg *lockRegistry
 }
 
 func (ctx *ctx) Err() error {
@@ -64,30 +142,33 @@ func (ctx *ctx) Get(key interface{}) (val interface{}, found bool) {
 	ctx.RLock()
 	defer ctx.RUnlock()
 
-	for i := len(ctx.kvs) - 1; i >= 0; i-- {
-		if val, found = ctx.kvs[i][key]; found {
-			break
-		}
-	}
-
-	return
+	e, found := ctx.store.get(key)
+	return e.val, found
 }
 
 func (ctx *ctx) Set(key interface{}, val interface{}) {
 	ctx.Lock()
-	ctx.kvs[len(ctx.kvs)-1][key] = val
+	ctx.store.set(key, val, Provenance{
+		Command: strings.Join(ctx.cmds, commandPathSep),
+		Time:    time.Now(),
+	})
 	ctx.Unlock()
 }
 
+func (ctx *ctx) Provenance(key interface{}) (prov Provenance, found bool) {
+	ctx.RLock()
+	defer ctx.RUnlock()
+
+	e, found := ctx.store.get(key)
+	return e.prov, found
+}
+
 func (ctx *ctx) push() {
-	ctx.kvs = append(ctx.kvs, make(hash))
+	ctx.store.push()
 }
 
 func (ctx *ctx) pop() {
-	if len(ctx.kvs) == 1 {
-		panic("cannot pop root context")
-	}
-	ctx.kvs = ctx.kvs[:len(ctx.kvs)-1]
+	ctx.store.pop()
 }
 
 func (ctx *ctx) unsetErr() {
@@ -95,3 +176,155 @@ func (ctx *ctx) unsetErr() {
 	ctx.err = nil
 	ctx.Unlock()
 }
+
+func (ctx *ctx) Done() <-chan struct{} {
+	return ctx.done
+}
+
+func (ctx *ctx) cancel() {
+	ctx.Lock()
+	select {
+	case <-ctx.done:
+		ctx.Unlock()
+		return
+	default:
+	}
+	close(ctx.done)
+	fns := ctx.onCancels
+	ctx.onCancels = nil
+	ctx.Unlock()
+
+	for _, fn := range fns {
+		fn()
+	}
+}
+
+func (ctx *ctx) onCancel(fn func()) (remove func()) {
+	ctx.Lock()
+	select {
+	case <-ctx.done:
+		ctx.Unlock()
+		fn()
+		return func() {}
+	default:
+	}
+
+	if ctx.onCancels == nil {
+		ctx.onCancels = make(map[int]func())
+	}
+	id := ctx.cancelID
+	ctx.cancelID++
+	ctx.onCancels[id] = fn
+	ctx.Unlock()
+
+	return func() {
+		ctx.Lock()
+		delete(ctx.onCancels, id)
+		ctx.Unlock()
+	}
+}
+
+func (ctx *ctx) locals() hash {
+	ctx.RLock()
+	defer ctx.RUnlock()
+	return ctx.store.locals()
+}
+
+func (ctx *ctx) layers() []layer {
+	ctx.RLock()
+	defer ctx.RUnlock()
+	return ctx.store.layers()
+}
+
+func (ctx *ctx) locks() *lockRegistry {
+	return ctx.lockReg
+}
+
+func (ctx *ctx) enter(cmd interface{}) {
+	ctx.Lock()
+	ctx.cmds = append(ctx.cmds, commandName(cmd))
+	ctx.stack = append(ctx.stack, cmd)
+	ctx.Unlock()
+}
+
+func (ctx *ctx) leave() {
+	ctx.Lock()
+	ctx.cmds = ctx.cmds[:len(ctx.cmds)-1]
+	ctx.stack = ctx.stack[:len(ctx.stack)-1]
+	ctx.Unlock()
+}
+
+func (ctx *ctx) path() []string {
+	ctx.RLock()
+	defer ctx.RUnlock()
+	return append([]string(nil), ctx.cmds...)
+}
+
+func (ctx *ctx) trace() []interface{} {
+	ctx.RLock()
+	defer ctx.RUnlock()
+	return append([]interface{}(nil), ctx.stack...)
+}
+
+func (ctx *ctx) self() Context {
+	return ctx
+}
+
+func (ctx *ctx) observe(o Observer) {
+	ctx.Lock()
+	ctx.observers = append(ctx.observers, o)
+	ctx.Unlock()
+}
+
+func (ctx *ctx) notify(e Event) {
+	ctx.RLock()
+	observers := ctx.observers
+	ctx.RUnlock()
+
+	for _, o := range observers {
+		o.Observe(e)
+	}
+}
+
+func (ctx *ctx) attempt(cmd interface{}, path string) int {
+	ctx.RLock()
+	defer ctx.RUnlock()
+	return ctx.attempts[commandID(cmd, path)] + 1
+}
+
+func (ctx *ctx) attempted(cmd interface{}, path string, failed bool) {
+	ctx.Lock()
+	defer ctx.Unlock()
+
+	id := commandID(cmd, path)
+	if !failed {
+		delete(ctx.attempts, id)
+		return
+	}
+
+	if ctx.attempts == nil {
+		ctx.attempts = make(map[interface{}]int)
+	}
+	ctx.attempts[id]++
+}
+
+// commandID identifies cmd by its pointer if it is referenced by one, so the same instance is identified wherever it
+// executes, or by name otherwise.
+func commandID(cmd interface{}, name string) interface{} {
+	if v := reflect.ValueOf(cmd); v.Kind() == reflect.Ptr {
+		return cmd
+	}
+	return name
+}
+
+// commandName returns the name of cmd used in its path: its String method if it is a fmt.Stringer, cmd itself if it is a
+// string, or its type otherwise.
+func commandName(cmd interface{}) string {
+	switch c := cmd.(type) {
+	case fmt.Stringer:
+		return c.String()
+	case string:
+		return c
+	}
+	return fmt.Sprintf("%T", cmd)
+}
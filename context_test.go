@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"errors"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -79,3 +80,106 @@ func TestContext_GetSet(t *testing.T) {
 	out, found = ctx.Get(key)
 	is.Equal(val, out, "parent should not know new value for kv set by children")
 }
+
+func TestContext_Cancel(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	ctx := NewContext()
+	select {
+	case <-ctx.Done():
+		is.Fail("new context should not be canceled")
+	default:
+	}
+
+	called := 0
+	ctx.onCancel(func() { called++ })
+
+	ctx.cancel()
+	ctx.cancel()
+	_, open := <-ctx.Done()
+	is.False(open, "done channel should be closed after cancel")
+	is.Equal(1, called, "cancel callbacks should be called exactly once")
+
+	ctx.onCancel(func() { called++ })
+	is.Equal(2, called, "callbacks registered after cancel should be called immediately")
+}
+
+func TestContext_Locals(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	ctx := NewContext()
+	ctx.Set("foo", "bar")
+	ctx.push()
+	ctx.Set("foo", "baz")
+	ctx.Set("fizz", "buzz")
+
+	is.Equal(hash{"foo": "baz", "fizz": "buzz"}, ctx.locals(), "locals should reflect shadowed values")
+}
+
+func TestContext_Provenance(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	ctx := NewContext()
+	_, found := ctx.Provenance("foo")
+	is.False(found, "unknown keys should have no provenance")
+
+	ctx.Set("foo", "bar")
+	prov, found := ctx.Provenance("foo")
+	is.True(found)
+	is.Empty(prov.Command, "values set outside a command should have no command")
+	is.Contains(prov.String(), "<none>")
+
+	ctx.push()
+	ctx.enter("outer")
+	ctx.enter("inner")
+	ctx.Set("foo", "baz")
+	ctx.leave()
+	ctx.leave()
+
+	prov, _ = ctx.Provenance("foo")
+	is.Equal("outer > inner", prov.Command)
+	is.WithinDuration(time.Now(), prov.Time, time.Second)
+
+	ctx.pop()
+	prov, _ = ctx.Provenance("foo")
+	is.Empty(prov.Command, "provenance should be popped with its layer")
+}
+
+func TestContext_Provenance_Run(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	ctx := NewContext()
+	NewSequence(
+		&MockCommand{name: "A", set: "foo"},
+		MakeParallel(&MockCommand{name: "B", set: "bar"}),
+	).Run(ctx, DefaultPrinter)
+
+	prov, _ := ctx.Provenance("foo")
+	is.Equal("MOCK A", prov.Command)
+
+	for _, e := range InspectContext(ctx) {
+		if sctx, ok := e.Value.([]Context); ok {
+			prov, _ = sctx[0].Provenance("bar")
+			is.Equal("1 Parallel Commands > MOCK B", prov.Command)
+		}
+	}
+}
+
+func TestContext_OnCancel_Remove(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	c := NewContext()
+	called := 0
+	remove := c.onCancel(func() { called++ })
+	remove()
+	remove()
+	is.Empty(c.(*ctx).onCancels, "removed callbacks should not be retained")
+
+	c.cancel()
+	is.Zero(called, "removed callbacks should not be called")
+}
@@ -0,0 +1,162 @@
+package runner
+
+import (
+	"context"
+	"time"
+)
+
+// An Option configures a call to Execute.
+type Option func(*options)
+
+type options struct {
+	printer   Printer
+	dryRun    bool
+	ctx       Context
+	values    map[interface{}]interface{}
+	observers []Observer
+	stdCtx    context.Context
+	signals   bool
+	result    *Result
+}
+
+// Result captures the outcome of a call to Execute. See WithResult.
+type Result struct {
+	// Context is the root Context the Commands were executed with.
+	Context Context
+
+	// Err is the error returned by Execute, if any.
+	Err error
+
+	// Duration is how long the Commands took to execute, including any rollback.
+	Duration time.Duration
+}
+
+// WithPrinter sets the Printer passed to all Commands for logging. By default, the DefaultPrinter is used.
+func WithPrinter(p Printer) Option {
+	return func(o *options) { o.printer = p }
+}
+
+// WithDryRun simulates the run of the Commands, without write/destructive actions, as in DryRun.
+func WithDryRun() Option {
+	return func(o *options) { o.dryRun = true }
+}
+
+// WithContext sets the root Context the Commands are executed with, as in RunWithContext. By default, a new Context is
+// created for each call to Execute.
+func WithContext(ctx Context) Option {
+	return func(o *options) { o.ctx = ctx }
+}
+
+// WithInitialValues sets the key-value pairs on the root Context before any Command is executed, making them available
+// to the Commands as inputs.
+func WithInitialValues(vals map[interface{}]interface{}) Option {
+	return func(o *options) {
+		if o.values == nil {
+			o.values = make(map[interface{}]interface{}, len(vals))
+		}
+		for k, v := range vals {
+			o.values[k] = v
+		}
+	}
+}
+
+// WithObserver registers an Observer to be notified as each Command starts and finishes executing. It may be specified
+// multiple times.
+func WithObserver(obs Observer) Option {
+	return func(o *options) { o.observers = append(o.observers, obs) }
+}
+
+// WithJournal records every Event of the run in j. It is equivalent to WithObserver(j).
+func WithJournal(j *Journal) Option {
+	return WithObserver(j)
+}
+
+// WithStdContext cancels the run when the context.Context is done. Cancellation behaves as described in
+// RunWithSignals: no new Commands are scheduled, in-flight Commands are allowed to complete, and every Command that ran
+// is rolled back. The run's error is ErrCanceled unless a Command set a different one first.
+func WithStdContext(sc context.Context) Option {
+	return func(o *options) { o.stdCtx = sc }
+}
+
+// WithSignals handles SIGINT and SIGTERM during the run, as described in RunWithSignals.
+func WithSignals() Option {
+	return func(o *options) { o.signals = true }
+}
+
+// WithProgress displays the progress of the run with pr, using it as both the Printer and an Observer. The caller is
+// responsible for closing pr once Execute returns.
+func WithProgress(pr *Progress) Option {
+	return func(o *options) {
+		o.printer = pr
+		o.observers = append(o.observers, pr)
+	}
+}
+
+// WithResult stores the outcome of the run in r once Execute returns.
+func WithResult(r *Result) Option {
+	return func(o *options) { o.result = r }
+}
+
+// Execute executes the passed in Commands in sequence, configured by opts, returning an error if the execution failed.
+// With no options, it is equivalent to Run.
+func Execute(cmds []Command, opts ...Option) error {
+	o := options{printer: DefaultPrinter}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	ctx := o.ctx
+	if ctx == nil {
+		ctx = NewContext()
+	}
+	for k, v := range o.values {
+		ctx.Set(k, v)
+	}
+	for _, obs := range o.observers {
+		ctx.observe(obs)
+	}
+
+	if o.stdCtx != nil {
+		done := make(chan struct{})
+		defer close(done)
+
+		go func() {
+			select {
+			case <-o.stdCtx.Done():
+				ctx.cancel()
+			case <-done:
+			}
+		}()
+	}
+
+	var sigs *signalHandler
+	if o.signals {
+		sigs = handleSignals(ctx, o.printer)
+		defer sigs.stop()
+	}
+
+	start := time.Now()
+	seq := &sequence{cmds: cmds}
+	if o.dryRun {
+		seq.DryRun(ctx, o.printer)
+	} else {
+		seq.Run(ctx, o.printer)
+	}
+
+	err := ctx.Err()
+	if sigs != nil {
+		if sig := sigs.received(); sig != nil && err != nil {
+			err = &SignalError{Signal: sig}
+		}
+	}
+
+	if o.result != nil {
+		*o.result = Result{
+			Context:  ctx,
+			Err:      err,
+			Duration: time.Since(start),
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,124 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecute(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+
+	cmdA := &MockCommand{name: "A"}
+	cmdB := &MockCommand{name: "B"}
+
+	is.NoError(Execute([]Command{cmdA, cmdB}))
+	is.True(cmdA.ran)
+	is.True(cmdB.ran)
+}
+
+func TestExecute_Failure(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	err := errors.New("foo")
+
+	cmdA := &MockCommand{name: "A"}
+	cmdB := &MockCommand{name: "B", err: err}
+
+	is.Equal(err, Execute([]Command{cmdA, cmdB}, WithPrinter(DefaultPrinter)))
+	is.True(cmdA.rolledBack)
+}
+
+func TestExecute_WithDryRun(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	cmd := &MockCommand{name: "A"}
+
+	is.NoError(Execute([]Command{cmd}, WithDryRun()))
+	is.True(cmd.dryRan)
+	is.False(cmd.ran)
+}
+
+func TestExecute_WithInitialValues(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+
+	var res Result
+	cmd := &MockCommand{name: "A", see: "in", set: "out"}
+
+	is.NoError(Execute([]Command{cmd},
+		WithInitialValues(map[interface{}]interface{}{"in": "foo"}),
+		WithResult(&res),
+	))
+	is.True(cmd.seenVal, "commands should see initial values")
+
+	is.NoError(res.Err)
+	is.NotZero(res.Duration)
+	val, found := res.Context.Get("out")
+	is.True(found, "outputs should be readable from the result")
+	is.Equal("A", val)
+}
+
+func TestExecute_WithContext(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	ctx := NewContext()
+
+	var res Result
+	is.NoError(Execute([]Command{&MockCommand{name: "A"}}, WithContext(ctx), WithResult(&res)))
+	is.Equal(ctx, res.Context)
+}
+
+func TestExecute_WithResult_Failure(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	err := errors.New("foo")
+
+	var res Result
+	is.Equal(err, Execute([]Command{&MockCommand{name: "A", err: err}}, WithResult(&res)))
+	is.Equal(err, res.Err)
+}
+
+func TestExecute_WithStdContext(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+
+	sc, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cmdA := &MockCommand{name: "A"}
+	cmdB := commandFunc(func(ctx Context, p Printer) {
+		cancel()
+		select {
+		case <-ctx.Done():
+		case <-time.After(time.Second):
+			ctx.SetErr(errors.New("not canceled"))
+		}
+	})
+	cmdC := &MockCommand{name: "C"}
+
+	is.Equal(ErrCanceled, Execute([]Command{cmdA, cmdB, cmdC}, WithStdContext(sc)))
+	is.True(cmdA.rolledBack, "previous commands should be rolled back")
+	is.False(cmdC.ran, "no new commands should be scheduled")
+}
+
+func TestExecute_WithJournal(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+
+	j := &Journal{}
+	is.NoError(Execute([]Command{&MockCommand{name: "A"}}, WithJournal(j)))
+	is.Len(j.Events(), 2)
+}
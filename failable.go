@@ -2,11 +2,12 @@ package runner
 
 import (
 	"fmt"
-	"math/rand"
+
+	"github.com/rodaine/runner/internal/namespace"
 )
 
 type failable struct {
-	id  string
+	key interface{}
 	cmd Command
 }
 
@@ -19,7 +20,7 @@ type failable struct {
 // This command implements the Rollbacker and DryRunner interfaces.
 func MakeFailable(cmd Command) Command {
 	return &failable{
-		id:  fmt.Sprintf("failable%d", rand.Int()),
+		key: namespace.New("failable").Key("error"),
 		cmd: cmd,
 	}
 }
@@ -34,7 +35,7 @@ func (f *failable) Run(ctx Context, p Printer) {
 }
 
 func (f *failable) Rollback(ctx Context, p Printer) {
-	if val, found := ctx.Get(f.id); found {
+	if val, found := ctx.Get(f.key); found {
 		if err, ok := val.(error); ok && err != nil {
 			p.Warn("skipping rollback due to failure: %v", err)
 			return
@@ -55,7 +56,7 @@ func (f *failable) DryRun(ctx Context, p Printer) {
 
 func (f *failable) suppressError(ctx Context, p Printer) {
 	err := ctx.Err()
-	ctx.Set(f.id, err)
+	ctx.Set(f.key, err)
 
 	if err != nil {
 		p.Warn("failure supressed: %v", err)
@@ -0,0 +1,266 @@
+package runner
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultRunID is the run ID used to name the log files of a FilePrinter if none is provided with FileRunID.
+const DefaultRunID = "run"
+
+// fileTimestampLayout formats the time a FilePrinter was created in its file names.
+const fileTimestampLayout = "20060102T150405.000Z"
+
+// A FileOption configures a FilePrinter.
+type FileOption func(*rotatingFile)
+
+// FileRunID sets the run ID used to name the log files. By default, DefaultRunID is used.
+func FileRunID(id string) FileOption {
+	return func(f *rotatingFile) { f.runID = id }
+}
+
+// FileMaxSize rotates to a new log file once the current one would exceed n bytes. A single message larger than n is
+// still written whole. By default, or if n is zero, files are never rotated. If the new file cannot be opened, the
+// FilePrinter is closed and further messages are discarded.
+func FileMaxSize(n int64) FileOption {
+	return func(f *rotatingFile) { f.maxSize = n }
+}
+
+// FileMaxFiles keeps at most n log files with the run ID in the directory, including those from previous runs, deleting
+// the oldest when a file is created. Other files in the directory are never deleted. By default, or if n is zero, all
+// files are kept.
+func FileMaxFiles(n int) FileOption {
+	return func(f *rotatingFile) { f.maxFiles = n }
+}
+
+// FileCompress compresses log files with gzip once they have been rotated, appending ".gz" to their names. Files that
+// cannot be compressed are left uncompressed.
+func FileCompress() FileOption {
+	return func(f *rotatingFile) { f.compress = true }
+}
+
+// FilePrinter is a Printer that writes to a log file per run, intended for runs executed on a schedule. Files are
+// created in a directory and named "<run ID>-<UTC timestamp>-<sequence>.log", with the sequence starting at 000 and
+// incremented each time the file is rotated. Messages are formatted as by NewPrinter.
+type FilePrinter struct {
+	FieldPrinter
+	file *rotatingFile
+}
+
+// NewFilePrinter creates dir if necessary and opens a new log file within it. LogLevels below the provided level are
+// suppressed from output. Close must be called once the run completes.
+func NewFilePrinter(dir string, level LogLevel, opts ...FileOption) (*FilePrinter, error) {
+	f := &rotatingFile{
+		dir:   dir,
+		runID: DefaultRunID,
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+	f.base = fmt.Sprintf("%s-%s", f.runID, time.Now().UTC().Format(fileTimestampLayout))
+	f.pattern = regexp.MustCompile(`^` + regexp.QuoteMeta(f.runID) + `-\d{8}T\d{6}\.\d{3}Z-\d{3,}\.log(\.gz)?$`)
+
+	if err := os.MkdirAll(dir, dirFileMode); err != nil {
+		return nil, err
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	if err := f.prune(); err != nil {
+		_ = f.f.Close()
+		return nil, err
+	}
+
+	return &FilePrinter{
+		FieldPrinter: NewPrinter(f, level).(FieldPrinter),
+		file:         f,
+	}, nil
+}
+
+// Path returns the path of the log file currently being written.
+func (fp *FilePrinter) Path() string {
+	fp.file.Lock()
+	defer fp.file.Unlock()
+	return fp.file.f.Name()
+}
+
+// Close closes the current log file. Messages logged after Close are discarded.
+func (fp *FilePrinter) Close() error {
+	fp.file.Lock()
+	defer fp.file.Unlock()
+
+	if fp.file.closed {
+		return nil
+	}
+	fp.file.closed = true
+	return fp.file.f.Close()
+}
+
+func (fp *FilePrinter) withRunFields(Fields) Printer {
+	return fp
+}
+
+func (fp *FilePrinter) enabled(level LogLevel) bool {
+	return printerEnabled(fp.FieldPrinter, level)
+}
+
+const (
+	dirFileMode os.FileMode = 0755
+	logFileMode os.FileMode = 0644
+)
+
+// rotatingFile is an io.Writer over a sequence of log files.
+type rotatingFile struct {
+	sync.Mutex
+
+	dir, runID, base string
+	pattern          *regexp.Regexp
+	maxSize          int64
+	maxFiles         int
+	compress         bool
+
+	f      *os.File
+	seq    int
+	size   int64
+	closed bool
+}
+
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	f.Lock()
+	defer f.Unlock()
+
+	if f.closed {
+		return 0, os.ErrClosed
+	}
+
+	var rerr error
+	if f.maxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.maxSize {
+		if rerr = f.rotate(); f.closed {
+			return 0, rerr
+		}
+	}
+
+	n, err := f.f.Write(p)
+	f.size += int64(n)
+	if err == nil {
+		err = rerr
+	}
+	return n, err
+}
+
+// open creates the next file in the sequence. Must be called with f locked.
+func (f *rotatingFile) open() error {
+	name := filepath.Join(f.dir, fmt.Sprintf("%s-%03d.log", f.base, f.seq))
+	file, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, logFileMode)
+	if err != nil {
+		return err
+	}
+
+	f.f, f.size = file, 0
+	return nil
+}
+
+// rotate closes the current file, compressing it if enabled, opens the next, and prunes old files. Failing to close or
+// compress the current file is reported, but the next file is still opened, leaving the current one uncompressed. If
+// the next file cannot be opened, f is marked closed so that further writes fail rather than write to the closed file.
+// Must be called with f locked.
+func (f *rotatingFile) rotate() error {
+	cerr := f.f.Close()
+	if cerr == nil && f.compress {
+		cerr = gzipFile(f.f.Name())
+	}
+
+	f.seq++
+	if err := f.open(); err != nil {
+		f.closed = true
+		return err
+	}
+
+	if err := f.prune(); err != nil {
+		return err
+	}
+	return cerr
+}
+
+// prune deletes the oldest log files of the run ID in the directory, keeping at most maxFiles. Must be called with f
+// locked.
+func (f *rotatingFile) prune() error {
+	if f.maxFiles <= 0 {
+		return nil
+	}
+
+	entries, err := os.ReadDir(f.dir)
+	if err != nil {
+		return err
+	}
+
+	type logFile struct {
+		name string
+		mod  time.Time
+	}
+
+	var logs []logFile
+	current := filepath.Base(f.f.Name())
+	for _, e := range entries {
+		name := e.Name()
+		if !e.Type().IsRegular() || name == current || !f.pattern.MatchString(name) {
+			continue
+		}
+
+		fi, err := e.Info()
+		if err != nil {
+			continue
+		}
+		logs = append(logs, logFile{name: name, mod: fi.ModTime()})
+	}
+
+	sort.Slice(logs, func(i, j int) bool {
+		if !logs[i].mod.Equal(logs[j].mod) {
+			return logs[i].mod.After(logs[j].mod)
+		}
+		return logs[i].name > logs[j].name
+	})
+
+	for i := f.maxFiles - 1; i < len(logs); i++ {
+		if err := os.Remove(filepath.Join(f.dir, logs[i].name)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// gzipFile compresses the file at path to path+".gz", removing the original.
+func gzipFile(path string) (err error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = src.Close() }()
+
+	dst, err := os.OpenFile(path+".gz", os.O_WRONLY|os.O_CREATE|os.O_EXCL, logFileMode)
+	if err != nil {
+		return err
+	}
+
+	zw := gzip.NewWriter(dst)
+	if _, err = io.Copy(zw, src); err == nil {
+		err = zw.Close()
+	}
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		_ = os.Remove(dst.Name())
+		return err
+	}
+
+	return os.Remove(path)
+}
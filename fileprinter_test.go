@@ -0,0 +1,192 @@
+package runner
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func listDir(t *testing.T, dir string) []string {
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+
+	names := make([]string, len(entries))
+	for i, e := range entries {
+		names[i] = e.Name()
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestFilePrinter(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	dir := filepath.Join(t.TempDir(), "logs")
+
+	p, err := NewFilePrinter(dir, LevelInfo, FileRunID("nightly"))
+	is.NoError(err)
+
+	p.Debug("foo")
+	p.WithPrefix("pre: ").Info("bar")
+	is.NoError(p.Close())
+	is.NoError(p.Close(), "closing twice should be a no-op")
+	p.Info("discarded")
+
+	names := listDir(t, dir)
+	is.Len(names, 1)
+	is.True(strings.HasPrefix(names[0], "nightly-"), names[0])
+	is.True(strings.HasSuffix(names[0], "-000.log"), names[0])
+	is.Equal(filepath.Join(dir, names[0]), p.Path())
+
+	b, err := os.ReadFile(p.Path())
+	is.NoError(err)
+	is.Equal("pre: bar\n", string(b))
+}
+
+func TestFilePrinter_Rotation(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	dir := t.TempDir()
+
+	p, err := NewFilePrinter(dir, LevelInfo, FileMaxSize(10), FileCompress())
+	is.NoError(err)
+	defer p.Close()
+
+	p.Info("12345678")
+	p.Info("abcdefgh")
+	p.Info("this line is longer than the max size")
+
+	names := listDir(t, dir)
+	is.Len(names, 3)
+	is.True(strings.HasSuffix(names[0], "-000.log.gz"), names[0])
+	is.True(strings.HasSuffix(names[1], "-001.log.gz"), names[1])
+	is.True(strings.HasSuffix(names[2], "-002.log"), names[2])
+
+	f, err := os.Open(filepath.Join(dir, names[1]))
+	is.NoError(err)
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	is.NoError(err)
+	b, err := io.ReadAll(zr)
+	is.NoError(err)
+	is.Equal("abcdefgh\n", string(b), "rotated files should be compressed")
+
+	b, err = os.ReadFile(p.Path())
+	is.NoError(err)
+	is.Equal("this line is longer than the max size\n", string(b))
+}
+
+func TestFilePrinter_Retention(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	dir := t.TempDir()
+
+	old := time.Now().Add(-time.Hour)
+	for i, name := range []string{
+		"nightly-20200102T030405.000Z-000.log",
+		"nightly-20200102T030405.000Z-001.log.gz",
+		"nightly-20200103T030405.000Z-000.log",
+		"other-20200101T030405.000Z-000.log",
+		"app.log",
+		"keep.txt",
+	} {
+		path := filepath.Join(dir, name)
+		is.NoError(os.WriteFile(path, nil, 0644))
+		is.NoError(os.Chtimes(path, old, old.Add(time.Duration(i)*time.Minute)))
+	}
+
+	p, err := NewFilePrinter(dir, LevelInfo, FileRunID("nightly"), FileMaxFiles(3), FileMaxSize(1))
+	is.NoError(err)
+	defer p.Close()
+
+	names := listDir(t, dir)
+	is.Equal([]string{
+		"app.log",
+		"keep.txt",
+		"nightly-20200102T030405.000Z-001.log.gz",
+		"nightly-20200103T030405.000Z-000.log",
+		filepath.Base(p.Path()),
+		"other-20200101T030405.000Z-000.log",
+	}, names, "oldest logs of the run ID should be removed and other files ignored")
+
+	p.Info("foo")
+	p.Info("bar")
+	names = listDir(t, dir)
+	is.Len(names, 6)
+	is.Contains(names, "app.log")
+	is.Contains(names, "keep.txt")
+	is.Contains(names, "other-20200101T030405.000Z-000.log")
+	is.NotContains(names, "nightly-20200102T030405.000Z-001.log.gz")
+}
+
+func TestFilePrinter_CompressError(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	dir := t.TempDir()
+
+	p, err := NewFilePrinter(dir, LevelInfo, FileMaxSize(1), FileCompress())
+	is.NoError(err)
+	defer p.Close()
+
+	p.Info("foo")
+	first := p.Path()
+	is.NoError(os.WriteFile(first+".gz", nil, 0644), "an existing file should cause compression to fail")
+
+	n, err := p.file.Write([]byte("bar\n"))
+	is.Error(err, "the compression error should be reported")
+	is.Equal(4, n, "the message should still be written")
+	p.Info("baz")
+
+	b, err := os.ReadFile(first)
+	is.NoError(err)
+	is.Equal("foo\n", string(b), "the file should be left uncompressed")
+
+	b, err = os.ReadFile(first + ".gz")
+	is.NoError(err)
+	is.Empty(b, "the existing file should be left alone")
+
+	names := listDir(t, dir)
+	is.Len(names, 4)
+	is.True(strings.HasSuffix(names[2], "-001.log.gz"), names[2])
+	is.True(strings.HasSuffix(names[3], "-002.log"), names[3])
+
+	b, err = os.ReadFile(p.Path())
+	is.NoError(err)
+	is.Equal("baz\n", string(b), "logging should continue after a compression failure")
+}
+
+func TestFilePrinter_RotateError(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	dir := t.TempDir()
+
+	p, err := NewFilePrinter(dir, LevelInfo, FileMaxSize(1))
+	is.NoError(err)
+	defer p.Close()
+
+	p.Info("foo")
+	next := strings.TrimSuffix(p.Path(), "-000.log") + "-001.log"
+	is.NoError(os.WriteFile(next, nil, 0644), "an existing file should cause opening the next file to fail")
+
+	_, err = p.file.Write([]byte("bar\n"))
+	is.Error(err)
+	_, err = p.file.Write([]byte("baz\n"))
+	is.Equal(os.ErrClosed, err, "a failed rotation should close the file")
+	is.NoError(p.Close())
+
+	b, err := os.ReadFile(p.Path())
+	is.NoError(err)
+	is.Equal("foo\n", string(b))
+}
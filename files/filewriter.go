@@ -11,6 +11,7 @@ import (
 	"syscall"
 
 	"github.com/rodaine/runner"
+	"github.com/rodaine/runner/internal/namespace"
 )
 
 const (
@@ -62,6 +63,7 @@ func WriteFile(sourceKey interface{}, destPath string) FileWriterCommand {
 		append:    DefaultFileWriterAppend,
 		rollback:  DefaultFileWriterRollback,
 		mode:      DefaultFileWriterFileMode,
+		ns:        namespace.New("WriteFile"),
 	}
 }
 
@@ -70,6 +72,7 @@ type fileWriter struct {
 	destPath         string
 	append, rollback bool
 	mode             os.FileMode
+	ns               namespace.Namespace
 }
 
 func (w *fileWriter) Run(ctx runner.Context, p runner.Printer) {
@@ -164,7 +167,7 @@ func (w *fileWriter) SetFileMode(mode os.FileMode) FileWriterCommand {
 func (w *fileWriter) getSource(ctx runner.Context, p runner.Printer) (r io.Reader, err error) {
 	data, found := ctx.Get(w.sourceKey)
 	if !found {
-		p.Warn("source data not found")
+		p.Warn("source data not found at key %v", w.sourceKey)
 		return &emptyReader{}, nil
 	}
 
@@ -177,7 +180,8 @@ func (w *fileWriter) getSource(ctx runner.Context, p runner.Printer) (r io.Reade
 		return bytes.NewBuffer(data.([]byte)), nil
 	}
 
-	err = fmt.Errorf("unsupported source data type: %T", data)
+	prov, _ := ctx.Provenance(w.sourceKey)
+	err = fmt.Errorf("unsupported source data type at key %v: %T (set by %v)", w.sourceKey, data, prov)
 	return &emptyReader{}, err
 }
 
@@ -223,20 +227,16 @@ func (w *fileWriter) fileSize(f *os.File, p runner.Printer) int64 {
 }
 
 func (w *fileWriter) setBytesWritten(ctx runner.Context, n int64) {
-	ctx.Set(w.bytesWrittenKey(), n)
+	w.bytesWrittenKey().Set(ctx, n)
 }
 
 func (w *fileWriter) bytesWritten(ctx runner.Context) (n int64) {
-	if val, ok := ctx.Get(w.bytesWrittenKey()); ok {
-		if n, ok = val.(int64); ok {
-			return
-		}
-	}
-	return 0
+	n, _ = w.bytesWrittenKey().Get(ctx)
+	return
 }
 
-func (w *fileWriter) bytesWrittenKey() interface{} {
-	return fmt.Sprintf("bytes written - %s", w.destPath)
+func (w *fileWriter) bytesWrittenKey() runner.Key[int64] {
+	return runner.KeyOf[int64](w.ns.Key("bytes written"))
 }
 
 type emptyReader struct{}
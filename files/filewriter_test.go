@@ -328,3 +328,23 @@ func TestFileWriter_truncateFile(t *testing.T) {
 
 	_ = f.Close()
 }
+
+func TestFileWriter_Rollback_SamePath(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	fn := prepTempFile()
+	defer cleanFile(fn)
+
+	ctx := runner.NewContext()
+	ctx.Set("foo", "foo")
+	ctx.Set("bar", "barbar")
+
+	a := WriteFile("foo", fn).SetAppend(true).SetRollback(true).(*fileWriter)
+	b := WriteFile("bar", fn).SetAppend(true).SetRollback(true).(*fileWriter)
+	a.Run(ctx, runner.DefaultPrinter)
+	b.Run(ctx, runner.DefaultPrinter)
+
+	is.Equal(int64(3), a.bytesWritten(ctx), "commands writing the same path should not share rollback data")
+	is.Equal(int64(6), b.bytesWritten(ctx))
+}
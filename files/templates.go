@@ -33,9 +33,10 @@ type tpl struct {
 func (t *tpl) Run(ctx runner.Context, p runner.Printer) {
 	data, found := ctx.Get(t.dataKey)
 	if !found {
-		p.Warn("template data not found")
+		p.Warn("template data not found at key %v", t.dataKey)
 	} else {
-		p.Debug("template data: %+v", data)
+		prov, _ := ctx.Provenance(t.dataKey)
+		p.Debug("template data (set by %v): %+v", prov, data)
 	}
 
 	wr := &bytes.Buffer{}
@@ -45,7 +46,7 @@ func (t *tpl) Run(ctx runner.Context, p runner.Printer) {
 		return
 	}
 
-	ctx.Set(t.outputKey, wr)
+	runner.KeyOf[io.Reader](t.outputKey).Set(ctx, wr)
 }
 
 func (t *tpl) DryRun(ctx runner.Context, p runner.Printer) {
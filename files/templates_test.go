@@ -74,8 +74,5 @@ func (t *ErrorTemplate) Execute(wr io.Writer, data interface{}) (err error) {
 }
 
 func fetchRenderedTemplate(ctx runner.Context) (r io.Reader, found bool) {
-	if val, ok := ctx.Get(outKey); ok {
-		r, found = val.(io.Reader)
-	}
-	return
+	return runner.KeyOf[io.Reader](outKey).Get(ctx)
 }
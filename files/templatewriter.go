@@ -1,17 +1,16 @@
 package files
 
 import (
-	"fmt"
-	"math/rand"
 	"os"
 
 	"github.com/rodaine/runner"
+	"github.com/rodaine/runner/internal/namespace"
 )
 
 // WriteTemplate returns a FileWriterCommand that resolves a Template from data on the Context and writes the
 // resulting output to the specified file.
 func WriteTemplate(template Template, dataKey interface{}, destPath string) FileWriterCommand {
-	renderedKey := fmt.Sprintf("RenderTemplateToFile-%d", rand.Int())
+	renderedKey := namespace.New("WriteTemplate").Key("rendered")
 	rdr := RenderTemplate(template, dataKey, renderedKey)
 	wrt := WriteFile(renderedKey, destPath)
 
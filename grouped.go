@@ -0,0 +1,223 @@
+package runner
+
+import "sync"
+
+// A GroupedPrinter describes a Printer that captures the output of each branch of a parallel Command (including
+// MakeQuorum and Race) and writes it as a contiguous block, preceded by a header naming the branch, once the branch
+// finishes. Output logged outside of a branch is written immediately.
+type GroupedPrinter interface {
+	FieldPrinter
+
+	// Group returns a Printer that captures the output of the branch with the given name, as well as a function that
+	// writes the captured output. Groups nested within another group are captured as part of the outer group, and their
+	// flush function is a no-op.
+	Group(name string) (p Printer, flush func())
+
+	// SetFlushLevel specifies the minimum LogLevel at which a message causes its group's captured output to be written
+	// immediately, rather than when the branch finishes; any further output is then captured as a new block. By default
+	// the level is LevelOff, so groups are only written when their branch finishes. This method mutates the underlying
+	// output shared by all Printers derived from it; the Printer is passed through for chaining convenience.
+	SetFlushLevel(level LogLevel) GroupedPrinter
+}
+
+// NewGroupedPrinter returns a GroupedPrinter that writes to p. Blocks are written while holding a lock shared by all
+// Printers derived from the returned Printer, so they are never interleaved with other output written through it.
+func NewGroupedPrinter(p Printer) GroupedPrinter {
+	return &groupedPrinter{
+		out: &groupedOutput{
+			p:          p,
+			flushLevel: LevelOff,
+		},
+	}
+}
+
+// branchPrinter returns the Printer for a parallel branch executing cmd, capturing its output if p is a
+// GroupedPrinter, and a function that must be called once the branch finishes.
+func branchPrinter(p Printer, cmd interface{}) (Printer, func()) {
+	if gp, ok := p.(GroupedPrinter); ok {
+		return gp.Group(commandName(cmd))
+	}
+	return p, func() {}
+}
+
+type groupedOutput struct {
+	sync.Mutex
+	p          Printer
+	flushLevel LogLevel
+}
+
+type groupedPrinter struct {
+	out    *groupedOutput
+	grp    *group
+	prefix string
+	fields Fields
+	run    Fields
+}
+
+// group is the captured output of a single branch.
+type group struct {
+	sync.Mutex
+	name    string
+	entries []groupEntry
+}
+
+// groupEntry is a captured message. It is formatted when captured, as its values may change before the group is flushed.
+type groupEntry struct {
+	level  LogLevel
+	prefix string
+	fields Fields
+	run    Fields
+	msg    string
+}
+
+func (p *groupedPrinter) Log(level LogLevel, format string, values ...interface{}) {
+	p.LogFields(level, nil, format, values...)
+}
+
+func (p *groupedPrinter) LogFields(level LogLevel, fields Fields, format string, values ...interface{}) {
+	e := groupEntry{
+		level:  level,
+		prefix: p.prefix,
+		fields: mergeFields(p.fields, fields),
+		run:    p.run,
+		msg:    sprintf(format, values...),
+	}
+
+	if p.grp == nil {
+		p.out.Lock()
+		p.out.write(e)
+		p.out.Unlock()
+		return
+	}
+
+	p.grp.Lock()
+	p.grp.entries = append(p.grp.entries, e)
+	p.grp.Unlock()
+
+	if level >= p.out.flushLevel {
+		p.grp.flush(p.out)
+	}
+}
+
+func (p *groupedPrinter) Trace(format string, values ...interface{}) {
+	p.Log(LevelTrace, format, values...)
+}
+
+func (p *groupedPrinter) Debug(format string, values ...interface{}) {
+	p.Log(LevelDebug, format, values...)
+}
+
+func (p *groupedPrinter) Info(format string, values ...interface{}) {
+	p.Log(LevelInfo, format, values...)
+}
+
+func (p *groupedPrinter) Warn(format string, values ...interface{}) {
+	p.Log(LevelWarn, format, values...)
+}
+
+func (p *groupedPrinter) Err(format string, values ...interface{}) {
+	p.Log(LevelError, format, values...)
+}
+
+func (p *groupedPrinter) Fatal(format string, values ...interface{}) {
+	p.Log(LevelFatal, format, values...)
+}
+
+func (p *groupedPrinter) WithPrefix(prefix string) Printer {
+	return &groupedPrinter{
+		out:    p.out,
+		grp:    p.grp,
+		prefix: p.prefix + prefix,
+		fields: p.fields,
+		run:    p.run,
+	}
+}
+
+func (p *groupedPrinter) WithFields(fields Fields) Printer {
+	return &groupedPrinter{
+		out:    p.out,
+		grp:    p.grp,
+		prefix: p.prefix,
+		fields: mergeFields(p.fields, fields),
+		run:    p.run,
+	}
+}
+
+func (p *groupedPrinter) enabled(level LogLevel) bool {
+	return printerEnabled(p.out.p, level)
+}
+
+func (p *groupedPrinter) withRunFields(fields Fields) Printer {
+	return &groupedPrinter{
+		out:    p.out,
+		grp:    p.grp,
+		prefix: p.prefix,
+		fields: p.fields,
+		run:    mergeFields(p.run, fields),
+	}
+}
+
+func (p *groupedPrinter) Group(name string) (Printer, func()) {
+	child := &groupedPrinter{
+		out:    p.out,
+		grp:    p.grp,
+		prefix: p.prefix,
+		fields: p.fields,
+		run:    p.run,
+	}
+
+	if p.grp != nil {
+		return child, func() {}
+	}
+
+	child.grp = &group{name: name}
+	return child, func() { child.grp.flush(p.out) }
+}
+
+func (p *groupedPrinter) SetFlushLevel(level LogLevel) GroupedPrinter {
+	p.out.flushLevel = level
+	return p
+}
+
+// flush writes the captured entries of g as a single block, preceded by a header at the highest LogLevel of the
+// entries so that it is only filtered out if all of them are.
+func (g *group) flush(out *groupedOutput) {
+	g.Lock()
+	entries := g.entries
+	g.entries = nil
+	g.Unlock()
+
+	if len(entries) == 0 {
+		return
+	}
+
+	level := entries[0].level
+	for _, e := range entries[1:] {
+		if e.level > level {
+			level = e.level
+		}
+	}
+
+	out.Lock()
+	defer out.Unlock()
+
+	out.p.Log(level, "--- %s", g.name)
+	for _, e := range entries {
+		out.write(e)
+	}
+}
+
+// write logs e to the underlying Printer. Must be called with out locked.
+func (out *groupedOutput) write(e groupEntry) {
+	p := out.p
+	if e.prefix != "" {
+		p = p.WithPrefix(e.prefix)
+	}
+	if len(e.run) > 0 {
+		p = addRunFields(p, e.run)
+	}
+	if len(e.fields) > 0 {
+		p = AddFields(p, e.fields)
+	}
+	p.Log(e.level, "%s", e.msg)
+}
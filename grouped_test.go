@@ -0,0 +1,103 @@
+package runner
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func chattyCommand(name string, delay time.Duration, err error) Command {
+	return commandFunc(func(ctx Context, p Printer) {
+		for i := 0; i < 3; i++ {
+			p.Info("%s %d", name, i)
+			time.Sleep(delay)
+		}
+		if err != nil {
+			p.Err("%s failed", name)
+			ctx.SetErr(err)
+		}
+	})
+}
+
+func TestGroupedPrinter_Parallel(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	buf := &syncBuffer{}
+	p := NewGroupedPrinter(NewPrinter(buf, LevelInfo))
+
+	p.Info("before")
+	is.NoError(RunWithPrinter(p, MakeParallel(
+		chattyCommand("A", 2*time.Millisecond, nil),
+		chattyCommand("B", time.Millisecond, nil),
+	)))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	is.Len(lines, 9)
+	is.Equal("before", lines[0], "output outside of a branch should be written immediately")
+
+	for _, block := range [][]string{lines[1:5], lines[5:9]} {
+		is.True(strings.HasPrefix(block[0], "--- "), "blocks should start with a header: %q", block[0])
+		name := block[1][:1]
+		for i, line := range block[1:] {
+			is.True(strings.HasPrefix(line, name+" "+string(rune('0'+i))), "block should be contiguous: %q", line)
+		}
+	}
+}
+
+func TestGroupedPrinter_FlushLevel(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	buf := &syncBuffer{}
+	p := NewGroupedPrinter(NewPrinter(buf, LevelInfo)).SetFlushLevel(LevelError)
+
+	bp, flush := p.Group("A")
+	bp.Info("foo")
+	is.Empty(buf.String(), "output should be captured until flushed")
+
+	bp.Err("bar")
+	is.Equal("--- A\nfoo\nbar\n", buf.String(), "errors should flush immediately")
+
+	nested, nestedFlush := bp.(GroupedPrinter).Group("B")
+	nested.WithPrefix("pre: ").Info("baz")
+	nestedFlush()
+	is.Equal("--- A\nfoo\nbar\n", buf.String(), "nested groups should be captured by the outer group")
+
+	flush()
+	is.Equal("--- A\nfoo\nbar\n--- A\npre: baz\n", buf.String())
+}
+
+func TestGroupedPrinter_HeaderLevel(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	buf := &syncBuffer{}
+	p := NewGroupedPrinter(NewPrinter(buf, LevelWarn))
+
+	err := errors.New("foo")
+	is.Equal(err, RunWithPrinter(p, MakeParallel(chattyCommand("A", 0, err))))
+	is.Contains(buf.String(), "--- ")
+	is.Contains(buf.String(), "A failed")
+	is.NotContains(buf.String(), "A 0", "captured output should still be filtered by level")
+}
+
+func TestGroupedPrinter_FormatOnCapture(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	buf := &syncBuffer{}
+	p := NewGroupedPrinter(NewPrinter(buf, LevelInfo))
+
+	bp, flush := p.Group("A")
+	vals := []int{1, 2}
+	bp.Info("vals: %v", vals)
+	vals[0] = 3
+	bp.Info("100%")
+	flush()
+
+	is.Equal("--- A\nvals: [1 2]\n100%\n", buf.String(), "messages should be formatted when captured")
+}
@@ -0,0 +1,96 @@
+package runner
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// A ContextEntry describes a single key-value pair stored in a Context, as returned by InspectContext.
+type ContextEntry struct {
+	// Key and Value are the stored key-value pair.
+	Key, Value interface{}
+
+	// Type is the dynamic type of Value, or nil if Value is nil.
+	Type reflect.Type
+
+	// Layer is the index of the layer that set this value, counting from the root Context's base layer (0). Sequences
+	// push a new layer for each Command they run.
+	Layer int
+
+	// Fork is the number of sub-contexts (such as parallel branches) between the layer and the root Context.
+	Fork int
+
+	// Depth is the number of layers above this one that also set Key. A value with a Depth of zero is the one returned
+	// by Context.Get; other values are shadowed.
+	Depth int
+
+	// SetBy records which Command set the value.
+	SetBy Provenance
+}
+
+func (e ContextEntry) String() string {
+	str := fmt.Sprintf("layer %d", e.Layer)
+	if e.Fork > 0 {
+		str += fmt.Sprintf(" (fork %d)", e.Fork)
+	}
+
+	str += fmt.Sprintf(": %v [%v] set by %v", e.Key, e.Type, e.SetBy)
+	if e.Depth > 0 {
+		str += fmt.Sprintf(" shadowed by %d", e.Depth)
+	}
+
+	return str
+}
+
+// InspectContext returns every key-value pair stored in the Context, including shadowed values and those inherited
+// from the parent of a sub-context. Entries are ordered from the topmost layer down; entries within a layer are
+// ordered by key. This function is intended for debugging; see DumpContext.
+func InspectContext(ctx Context) []ContextEntry {
+	layers := ctx.layers()
+	seen := make(map[interface{}]int)
+
+	var out []ContextEntry
+	for i := len(layers) - 1; i >= 0; i-- {
+		entries := make([]ContextEntry, 0, len(layers[i].kvs))
+		for k, v := range layers[i].kvs {
+			entries = append(entries, ContextEntry{
+				Key:   k,
+				Value: v,
+				Type:  reflect.TypeOf(v),
+				Layer: i,
+				Fork:  layers[i].fork,
+				Depth: seen[k],
+				SetBy: layers[i].prov[k],
+			})
+			seen[k]++
+		}
+
+		sort.Slice(entries, func(a, b int) bool {
+			return fmt.Sprint(entries[a].Key) < fmt.Sprint(entries[b].Key)
+		})
+		out = append(out, entries...)
+	}
+
+	return out
+}
+
+// DumpContext writes every entry returned by InspectContext to the Printer at the Trace LogLevel. Sequences call this
+// after each Command is run. The Context is only inspected if the Printer would output Trace messages; Printers not
+// provided by this package are assumed not to.
+func DumpContext(ctx Context, p Printer) {
+	if !traceEnabled(p) {
+		return
+	}
+
+	entries := InspectContext(ctx)
+	p.Trace("context: %d entries", len(entries))
+	for _, e := range entries {
+		p.Trace("context: %v", e)
+	}
+}
+
+// traceEnabled reports whether p may output Trace messages, avoiding the cost of inspecting the Context otherwise.
+func traceEnabled(p Printer) bool {
+	return printerEnabled(p, LevelTrace)
+}
@@ -0,0 +1,116 @@
+package runner
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInspectContext(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+
+	ctx := NewContext()
+	ctx.Set("foo", "bar")
+	ctx.Set("fizz", 123)
+	ctx.push()
+	ctx.Set("foo", "baz")
+
+	sctx := newSubContext(ctx)
+	sctx.Set("foo", nil)
+
+	entries := InspectContext(sctx)
+	for i := range entries {
+		is.False(entries[i].SetBy.Time.IsZero(), "entries should record when they were set")
+		entries[i].SetBy = Provenance{}
+	}
+
+	is.Equal([]ContextEntry{
+		{Key: "foo", Value: nil, Type: nil, Layer: 2, Fork: 1, Depth: 0},
+		{Key: "foo", Value: "baz", Type: reflect.TypeOf(""), Layer: 1, Fork: 0, Depth: 1},
+		{Key: "fizz", Value: 123, Type: reflect.TypeOf(0), Layer: 0, Fork: 0, Depth: 0},
+		{Key: "foo", Value: "bar", Type: reflect.TypeOf(""), Layer: 0, Fork: 0, Depth: 2},
+	}, entries)
+
+	is.Len(InspectContext(ctx), 3, "parent should not see subcontext entries")
+}
+
+func TestContextEntry_String(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+
+	str := fmt.Sprint(ContextEntry{Key: "foo", Type: reflect.TypeOf(""), Layer: 3, Fork: 1, Depth: 2})
+	is.Contains(str, "layer 3")
+	is.Contains(str, "fork 1")
+	is.Contains(str, "foo [string]")
+	is.Contains(str, "shadowed by 2")
+
+	str = fmt.Sprint(ContextEntry{Key: "foo", Type: reflect.TypeOf(0)})
+	is.NotContains(str, "fork")
+	is.NotContains(str, "shadowed")
+}
+
+func TestDumpContext(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	p, out := getTestPrinter()
+
+	ctx := NewContext()
+	ctx.Set("foo", "bar")
+	DumpContext(ctx, p.WithPrefix("> "))
+	is.Contains(out.String(), "> context: 1 entries")
+	is.Contains(out.String(), "> context: layer 0: foo [string]")
+
+	out.Reset()
+	p.level = LevelDebug
+	DumpContext(ctx, p.WithPrefix("> "))
+	is.Empty(out.String(), "nothing should be dumped unless tracing")
+}
+
+func TestSequence_Run_DumpsContext(t *testing.T) {
+	t.Parallel()
+
+	buf := &bytes.Buffer{}
+	err := RunWithPrinter(NewPrinter(buf, LevelTrace), &MockCommand{name: "A", set: "foo"})
+
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), "context: layer 2: foo [string]")
+}
+
+func TestTraceEnabled(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	file, err := NewFilePrinter(t.TempDir(), LevelTrace)
+	is.NoError(err)
+	defer file.Close()
+
+	tests := []struct {
+		p    Printer
+		want bool
+	}{
+		{NewPrinter(io.Discard, LevelTrace).WithPrefix("> "), true},
+		{NewPrinter(io.Discard, LevelInfo).WithPrefix("> "), false},
+		{AddFields(NewJSONPrinter(io.Discard, LevelInfo), Fields{"foo": "bar"}), false},
+		{NewJSONPrinter(io.Discard, LevelTrace), true},
+		{NewTerminalPrinter(io.Discard, LevelInfo), false},
+		{NewGroupedPrinter(NewPrinter(io.Discard, LevelTrace)), true},
+		{MultiPrinter(NewPrinter(io.Discard, LevelInfo), NewJSONPrinter(io.Discard, LevelTrace)), true},
+		{MultiPrinter(NewPrinter(io.Discard, LevelInfo)), false},
+		{NewProgress(io.Discard, LevelInfo), false},
+		{file, true},
+		{&fieldsPrinter{Printer: NewPrinter(io.Discard, LevelTrace)}, true},
+		{struct{ Printer }{NewPrinter(io.Discard, LevelTrace)}, false},
+	}
+
+	for i, test := range tests {
+		is.Equal(test.want, traceEnabled(test.p), "printer %d", i)
+	}
+}
@@ -19,6 +19,10 @@ type Command interface {
 // will have access to the same Context when the Command's Run method was executed. The error that triggered the
 // rollback may not be available in the Context, so its value should not be relied upon.
 //
+// The Context passed to Rollback is read-only. Values set during a rollback are only visible to the Rollbacker itself
+// and are discarded once it returns, and errors set on it are ignored; failures should be reported via the Printer
+// instead. This applies equally to the sub-contexts of parallel Commands.
+//
 // Commands that don't implement Rollbacker will be skipped over during a rollback; they will not halt the execution.
 type Rollbacker interface {
 	Rollback(Context, Printer)
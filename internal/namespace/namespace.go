@@ -0,0 +1,68 @@
+// Package namespace provides Context keys that are private to a single Command instance, for storing the internal
+// state of the Commands provided by runner and its sub-packages.
+package namespace
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+)
+
+// A Namespace produces Context keys that are private to a single Command instance. Keys from different Namespaces
+// never collide with each other, nor with keys created outside of a Namespace, making them suitable for storing a
+// Command's internal state (such as data needed for a rollback) on the Context.
+//
+// Namespaces are identified by a counter local to the process, so keys only match those of a Namespace created by the
+// same process.
+type Namespace struct {
+	id   uint64
+	name string
+}
+
+var namespaces uint64
+
+// New returns a new, unique Namespace. The name is descriptive only and appears when keys are printed.
+func New(name string) Namespace {
+	return Namespace{
+		id:   atomic.AddUint64(&namespaces, 1),
+		name: name,
+	}
+}
+
+// Key returns a Context key within the Namespace. Calling Key with the same name on the same Namespace returns equal
+// keys.
+func (ns Namespace) Key(name string) interface{} {
+	return Key{ns: ns, name: name}
+}
+
+// Key is the type of the keys returned by Namespace.Key. It is exported so that keys can be serialized; it encodes to
+// and from JSON.
+type Key struct {
+	ns   Namespace
+	name string
+}
+
+func (k Key) String() string {
+	return fmt.Sprintf("%s#%d.%s", k.ns.name, k.ns.id, k.name)
+}
+
+type jsonKey struct {
+	ID        uint64 `json:"id"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+// MarshalJSON encodes the key, including the identity of its Namespace.
+func (k Key) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonKey{ID: k.ns.id, Namespace: k.ns.name, Name: k.name})
+}
+
+// UnmarshalJSON decodes a key encoded by MarshalJSON.
+func (k *Key) UnmarshalJSON(b []byte) error {
+	var jk jsonKey
+	if err := json.Unmarshal(b, &jk); err != nil {
+		return err
+	}
+	*k = Key{ns: Namespace{id: jk.ID, name: jk.Namespace}, name: jk.Name}
+	return nil
+}
@@ -0,0 +1,45 @@
+package namespace
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNamespace_Key(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	a := New("foo")
+	b := New("foo")
+
+	is.Equal(a.Key("bar"), a.Key("bar"), "keys from the same namespace should be equal")
+	is.NotEqual(a.Key("bar"), a.Key("baz"))
+	is.NotEqual(a.Key("bar"), b.Key("bar"), "keys from different namespaces should never collide")
+	is.NotEqual("bar", a.Key("bar"))
+	is.NotEqual(fmt.Sprint(a.Key("bar")), a.Key("bar"))
+}
+
+func TestNamespace_String(t *testing.T) {
+	t.Parallel()
+
+	assert.Regexp(t, `^foo#\d+\.bar$`, fmt.Sprint(New("foo").Key("bar")))
+}
+
+func TestKey_JSON(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	key := New("foo").Key("bar")
+
+	b, err := json.Marshal(key)
+	is.NoError(err)
+
+	var out Key
+	is.NoError(json.Unmarshal(b, &out))
+	is.Equal(key, out)
+
+	is.Error(json.Unmarshal([]byte(`[]`), &out))
+}
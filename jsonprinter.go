@@ -0,0 +1,153 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// NewJSONPrinter returns a Printer which writes each message to the provided io.Writer as a single line of JSON (JSON
+// Lines). LogLevels below the provided level are suppressed from output. Each object contains:
+//
+//	time:   when the message was logged, in RFC 3339 format
+//	level:  the name of the LogLevel, such as "info"
+//	prefix: the prefixes added via WithPrefix, outermost first (omitted if empty)
+//	msg:    the formatted message, without prefixes
+//	format: the format string passed to the Printer
+//	args:   the values passed to the Printer (omitted if empty)
+//	fields: the structured Fields attached to the message (omitted if empty)
+//
+// Errors are encoded as their message, and any other args or fields that cannot be encoded as JSON are encoded as
+// strings via fmt.Sprint. Each line is written with a single call to Write, and writes are serialized across all
+// Printers derived from the returned Printer.
+func NewJSONPrinter(w io.Writer, level LogLevel) Printer {
+	return &jsonPrinter{
+		out: &jsonOutput{
+			w:     w,
+			level: level,
+			now:   time.Now,
+		},
+	}
+}
+
+type jsonOutput struct {
+	sync.Mutex
+	w     io.Writer
+	level LogLevel
+	now   func() time.Time
+}
+
+type jsonPrinter struct {
+	out    *jsonOutput
+	prefix []string
+	fields Fields
+}
+
+type jsonRecord struct {
+	Time   time.Time     `json:"time"`
+	Level  string        `json:"level"`
+	Prefix []string      `json:"prefix,omitempty"`
+	Msg    string        `json:"msg"`
+	Format string        `json:"format"`
+	Args   []interface{} `json:"args,omitempty"`
+	Fields Fields        `json:"fields,omitempty"`
+}
+
+func (p *jsonPrinter) Log(level LogLevel, format string, values ...interface{}) {
+	p.LogFields(level, nil, format, values...)
+}
+
+func (p *jsonPrinter) LogFields(level LogLevel, fields Fields, format string, values ...interface{}) {
+	if level < p.out.level {
+		return
+	}
+
+	rec := jsonRecord{
+		Time:   p.out.now(),
+		Level:  level.String(),
+		Prefix: p.prefix,
+		Msg:    sprintf(format, values...),
+		Format: format,
+	}
+
+	if len(values) > 0 {
+		rec.Args = make([]interface{}, len(values))
+		for i, v := range values {
+			rec.Args[i] = jsonSafe(v)
+		}
+	}
+
+	if fields = mergeFields(p.fields, fields); len(fields) > 0 {
+		rec.Fields = make(Fields, len(fields))
+		for k, v := range fields {
+			rec.Fields[k] = jsonSafe(v)
+		}
+	}
+
+	b, err := json.Marshal(rec)
+	if err != nil {
+		b, _ = json.Marshal(jsonRecord{Time: rec.Time, Level: rec.Level, Msg: fmt.Sprintf("unable to encode log: %v", err)})
+	}
+
+	p.out.Lock()
+	_, _ = p.out.w.Write(append(b, '\n'))
+	p.out.Unlock()
+}
+
+func (p *jsonPrinter) Trace(format string, values ...interface{}) {
+	p.Log(LevelTrace, format, values...)
+}
+
+func (p *jsonPrinter) Debug(format string, values ...interface{}) {
+	p.Log(LevelDebug, format, values...)
+}
+
+func (p *jsonPrinter) Info(format string, values ...interface{}) {
+	p.Log(LevelInfo, format, values...)
+}
+
+func (p *jsonPrinter) Warn(format string, values ...interface{}) {
+	p.Log(LevelWarn, format, values...)
+}
+
+func (p *jsonPrinter) Err(format string, values ...interface{}) {
+	p.Log(LevelError, format, values...)
+}
+
+func (p *jsonPrinter) Fatal(format string, values ...interface{}) {
+	p.Log(LevelFatal, format, values...)
+}
+
+func (p *jsonPrinter) WithPrefix(prefix string) Printer {
+	return &jsonPrinter{
+		out:    p.out,
+		prefix: append(p.prefix[:len(p.prefix):len(p.prefix)], prefix),
+		fields: p.fields,
+	}
+}
+
+func (p *jsonPrinter) WithFields(fields Fields) Printer {
+	return &jsonPrinter{
+		out:    p.out,
+		prefix: p.prefix,
+		fields: mergeFields(p.fields, fields),
+	}
+}
+
+func (p *jsonPrinter) enabled(level LogLevel) bool {
+	return level >= p.out.level
+}
+
+// jsonSafe returns v if it can be encoded as JSON, or fmt.Sprint(v) otherwise, which is the message of v if it is an
+// error. Like fmt, a nil error of a pointer type is encoded as "<nil>".
+func jsonSafe(v interface{}) interface{} {
+	if _, ok := v.(error); ok {
+		return fmt.Sprint(v)
+	}
+	if _, err := json.Marshal(v); err != nil {
+		return fmt.Sprint(v)
+	}
+	return v
+}
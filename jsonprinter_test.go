@@ -0,0 +1,96 @@
+package runner
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getJSONTestPrinter(level LogLevel) (*jsonPrinter, *bytes.Buffer) {
+	buf := &bytes.Buffer{}
+	p := NewJSONPrinter(buf, level).(*jsonPrinter)
+	p.out.now = func() time.Time { return time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC) }
+	return p, buf
+}
+
+func TestJSONPrinter_Log(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	p, out := getJSONTestPrinter(LevelInfo)
+
+	p.Debug("foo")
+	is.Empty(out.String())
+
+	p.Info("foo")
+	is.Equal(`{"time":"2020-01-02T03:04:05Z","level":"info","msg":"foo","format":"foo"}`+"\n", out.String())
+}
+
+func TestJSONPrinter_Fields(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	p, out := getJSONTestPrinter(LevelAll)
+
+	fp := AddFields(p.WithPrefix("foo: "), Fields{"fizz": "buzz", "ch": make(chan int)}).WithPrefix("bar: ")
+	fp.Err("%d bytes written to %s: %v", 5, "/tmp/x", errors.New("boom"))
+
+	var rec map[string]interface{}
+	is.NoError(json.Unmarshal(out.Bytes(), &rec))
+	is.Equal("error", rec["level"])
+	is.Equal([]interface{}{"foo: ", "bar: "}, rec["prefix"])
+	is.Equal("5 bytes written to /tmp/x: boom", rec["msg"])
+	is.Equal("%d bytes written to %s: %v", rec["format"])
+	is.Equal([]interface{}{5.0, "/tmp/x", "boom"}, rec["args"])
+
+	fields := rec["fields"].(map[string]interface{})
+	is.Equal("buzz", fields["fizz"])
+	is.True(strings.HasPrefix(fields["ch"].(string), "0x"), "unencodable fields should be stringified")
+}
+
+func TestJSONPrinter_WithPrefix_Siblings(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	p, out := getJSONTestPrinter(LevelAll)
+
+	parent := p.WithPrefix("a")
+	parent.WithPrefix("b")
+	parent.WithPrefix("c").Info("foo")
+
+	var rec jsonRecord
+	is.NoError(json.Unmarshal(out.Bytes(), &rec))
+	is.Equal([]string{"a", "c"}, rec.Prefix, "sibling prefixes should not share state")
+}
+
+func TestLogLevel_String(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	is.Equal("warn", LevelWarn.String())
+	is.Equal("LogLevel(42)", LogLevel(42).String())
+}
+
+func TestJSONPrinter_NilError(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	p, out := getJSONTestPrinter(LevelAll)
+
+	var err *os.PathError
+	is.NotPanics(func() {
+		AddFields(p, Fields{"err": err}).Info("failed: %v", err)
+	}, "typed nil errors should not panic")
+
+	var rec jsonRecord
+	is.NoError(json.Unmarshal(out.Bytes(), &rec))
+	is.Equal("failed: <nil>", rec.Msg)
+	is.Equal([]interface{}{"<nil>"}, rec.Args)
+	is.Equal("<nil>", rec.Fields["err"])
+}
@@ -0,0 +1,106 @@
+package runner
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// A Key provides type-safe access to a value stored in a Context. A Key is a thin typed view over an ordinary Context
+// key, so values set through a Key can be read with Context.Get and vice versa.
+type Key[T any] struct {
+	key interface{}
+}
+
+// NewKey returns a Key of type T stored under name.
+func NewKey[T any](name string) Key[T] {
+	return KeyOf[T](name)
+}
+
+// KeyOf returns a Key of type T for an existing, untyped Context key.
+func KeyOf[T any](key interface{}) Key[T] {
+	return Key[T]{key: key}
+}
+
+// KeyError describes a failed lookup of a Key, either because no value was found or because the value is not of the
+// Key's type.
+type KeyError struct {
+	// Key is the underlying Context key.
+	Key interface{}
+
+	// Want is the type of the Key.
+	Want reflect.Type
+
+	// Got is the value found in the Context. It is only meaningful if Found is true.
+	Got interface{}
+
+	// Found indicates whether or not a value was found for Key.
+	Found bool
+
+	// SetBy records which Command set the value found for Key. It is only meaningful if Found is true.
+	SetBy Provenance
+}
+
+func (e *KeyError) Error() string {
+	if !e.Found {
+		return fmt.Sprintf("context key %v (%v) not found", e.Key, e.Want)
+	}
+	return fmt.Sprintf("context key %v: value of type %T (set by %v) is not %v", e.Key, e.Got, e.SetBy, e.Want)
+}
+
+// Get returns the value of the Key from the Context, as well as whether or not a value of type T was found. Use Lookup
+// to distinguish between a missing value and one of the wrong type.
+func (k Key[T]) Get(ctx Context) (val T, found bool) {
+	val, err := k.Lookup(ctx)
+	return val, err == nil
+}
+
+// Lookup returns the value of the Key from the Context. A *KeyError is returned if the value is missing or is not of
+// type T.
+func (k Key[T]) Lookup(ctx Context) (val T, err error) {
+	raw, found := ctx.Get(k.key)
+	if !found {
+		return val, &KeyError{Key: k.key, Want: k.typ()}
+	}
+
+	if raw == nil && k.nillable() {
+		return val, nil
+	}
+
+	if val, ok := raw.(T); ok {
+		return val, nil
+	}
+
+	prov, _ := ctx.Provenance(k.key)
+	return val, &KeyError{Key: k.key, Want: k.typ(), Got: raw, Found: true, SetBy: prov}
+}
+
+// MustGet returns the value of the Key from the Context, panicking with a *KeyError if it is missing or is not of
+// type T.
+func (k Key[T]) MustGet(ctx Context) T {
+	val, err := k.Lookup(ctx)
+	if err != nil {
+		panic(err)
+	}
+	return val
+}
+
+// Set stores val on the Context under the Key.
+func (k Key[T]) Set(ctx Context, val T) {
+	ctx.Set(k.key, val)
+}
+
+func (k Key[T]) String() string {
+	return fmt.Sprintf("%v (%v)", k.key, k.typ())
+}
+
+func (k Key[T]) typ() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+func (k Key[T]) nillable() bool {
+	switch k.typ().Kind() {
+	case reflect.Interface, reflect.Ptr, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,105 @@
+package runner
+
+import (
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKey_GetSet(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	ctx := NewContext()
+	key := NewKey[int]("foo")
+
+	_, found := key.Get(ctx)
+	is.False(found)
+
+	key.Set(ctx, 123)
+	val, found := key.Get(ctx)
+	is.True(found)
+	is.Equal(123, val)
+
+	raw, _ := ctx.Get("foo")
+	is.Equal(123, raw, "keys should be interoperable with untyped access")
+
+	ctx.Set("foo", "bar")
+	_, found = key.Get(ctx)
+	is.False(found, "mismatched types should not be found")
+}
+
+func TestKey_Lookup(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	ctx := NewContext()
+	key := NewKey[string]("foo")
+
+	_, err := key.Lookup(ctx)
+	is.IsType(&KeyError{}, err)
+	is.Contains(err.Error(), "not found")
+
+	ctx.Set("foo", 123)
+	_, err = key.Lookup(ctx)
+	is.IsType(&KeyError{}, err)
+	is.Contains(err.Error(), "int (set by <none>")
+	is.Contains(err.Error(), "is not string")
+
+	key.Set(ctx, "bar")
+	val, err := key.Lookup(ctx)
+	is.NoError(err)
+	is.Equal("bar", val)
+}
+
+func TestKey_Lookup_Nil(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	ctx := NewContext()
+	ctx.Set("foo", nil)
+
+	r, err := KeyOf[io.Reader]("foo").Lookup(ctx)
+	is.NoError(err, "nil should be a valid interface value")
+	is.Nil(r)
+
+	_, err = KeyOf[int]("foo").Lookup(ctx)
+	is.Error(err, "nil should not be a valid int value")
+}
+
+func TestKey_MustGet(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	ctx := NewContext()
+	key := NewKey[int]("foo")
+
+	is.Panics(func() { key.MustGet(ctx) })
+
+	key.Set(ctx, 123)
+	is.Equal(123, key.MustGet(ctx))
+}
+
+func TestKey_KeyOf(t *testing.T) {
+	t.Parallel()
+
+	type private struct{}
+
+	is := assert.New(t)
+	ctx := NewContext()
+	ctx.Set(private{}, 1.5)
+
+	val, found := KeyOf[float64](private{}).Get(ctx)
+	is.True(found)
+	is.Equal(1.5, val)
+}
+
+func TestKey_String(t *testing.T) {
+	t.Parallel()
+
+	str := fmt.Sprint(NewKey[[]byte]("foo"))
+	assert.Contains(t, str, "foo")
+	assert.Contains(t, str, "[]uint8")
+}
@@ -0,0 +1,247 @@
+package runner
+
+import (
+	"fmt"
+	"sync"
+)
+
+// WithLock returns a Command that holds the named lock while executing cmd. Locks are scoped to a single run and are
+// shared by every Command within it, including those in nested parallel Commands. The lock is also held while cmd is
+// rolled back or dry run. It is equivalent to WithSemaphore(name, 1, cmd).
+//
+// If acquiring the lock would deadlock, such as when it is already held by this Command's branch or by a branch that
+// is waiting on this one, cmd is not executed and a DeadlockError is set on the Context. A deadlock during a rollback
+// is logged and the rollback of cmd is skipped. If the Context is canceled while waiting for the lock, cmd is not
+// executed and ErrCanceled is set on the Context; rollbacks wait for the lock regardless of cancellation.
+//
+// This command implements the Rollbacker and DryRunner interfaces.
+func WithLock(name string, cmd Command) Command {
+	return WithSemaphore(name, 1, cmd)
+}
+
+// WithSemaphore returns a Command that holds one of n permits of the named semaphore while executing cmd. All
+// WithSemaphore Commands using the same name within a run must specify the same n. Otherwise, it behaves identically
+// to WithLock. WithSemaphore panics if n is less than 1, as no permit could ever be acquired.
+//
+// This command implements the Rollbacker and DryRunner interfaces.
+func WithSemaphore(name string, n int, cmd Command) Command {
+	if n < 1 {
+		panic(fmt.Sprintf("semaphore %q requires at least 1 permit, got %d", name, n))
+	}
+
+	return &locked{
+		name: name,
+		n:    n,
+		cmd:  cmd,
+	}
+}
+
+// DeadlockError is set on the Context when acquiring a lock or semaphore permit would never succeed because every
+// holder is, directly or indirectly, waiting on the acquiring Command.
+type DeadlockError struct {
+	// Name is the lock or semaphore that could not be acquired.
+	Name string
+}
+
+func (e *DeadlockError) Error() string {
+	return fmt.Sprintf("deadlock detected acquiring %q: all holders are waiting on this command", e.Name)
+}
+
+type locked struct {
+	name string
+	n    int
+	cmd  Command
+}
+
+func (l *locked) String() string {
+	if l.n == 1 {
+		return fmt.Sprintf("%s [lock %s]", l.cmd, l.name)
+	}
+	return fmt.Sprintf("%s [semaphore %s/%d]", l.cmd, l.name, l.n)
+}
+
+func (l *locked) Run(ctx Context, p Printer) {
+	release, err := ctx.locks().acquire(ctx, l.name, l.n, true)
+	if err != nil {
+		p.Err("%v", err)
+		ctx.SetErr(err)
+		return
+	}
+	defer release()
+
+	l.cmd.Run(ctx, p)
+}
+
+func (l *locked) Rollback(ctx Context, p Printer) {
+	cmd, ok := l.cmd.(Rollbacker)
+	if !ok {
+		return
+	}
+
+	release, err := ctx.locks().acquire(ctx, l.name, l.n, false)
+	if err != nil {
+		p.Err("skipping rollback: %v", err)
+		return
+	}
+	defer release()
+
+	cmd.Rollback(ctx, p)
+}
+
+func (l *locked) DryRun(ctx Context, p Printer) {
+	cmd, ok := l.cmd.(DryRunner)
+	if !ok {
+		return
+	}
+
+	release, err := ctx.locks().acquire(ctx, l.name, l.n, true)
+	if err != nil {
+		p.Err("%v", err)
+		ctx.SetErr(err)
+		return
+	}
+	defer release()
+
+	cmd.DryRun(ctx, p)
+}
+
+// lockRegistry tracks the named semaphores of a run. Holders and waiters are identified by their Context: Commands in
+// a sequence share a Context, while each parallel branch receives its own sub-context. Views over a Context (such as
+// those created by Scope) are identified by the Context they wrap.
+type lockRegistry struct {
+	sync.Mutex
+	cond *sync.Cond
+
+	sems    map[string]*semaphore
+	waiting map[Context]*semaphore
+}
+
+type semaphore struct {
+	name    string
+	n       int
+	held    int
+	holders map[Context]int
+}
+
+func newLockRegistry() *lockRegistry {
+	r := &lockRegistry{
+		sems:    make(map[string]*semaphore),
+		waiting: make(map[Context]*semaphore),
+	}
+	r.cond = sync.NewCond(r)
+	return r
+}
+
+// acquire blocks until a permit of the named semaphore is available to holder. If cancelable, it returns ErrCanceled
+// once holder's Context is canceled.
+func (r *lockRegistry) acquire(holder Context, name string, n int, cancelable bool) (release func(), err error) {
+	var done <-chan struct{}
+	if cancelable {
+		done = holder.Done()
+
+		// waiters are woken on cancellation; locking first ensures a waiter is either parked or has not yet checked done.
+		remove := holder.onCancel(func() {
+			r.Lock()
+			r.Unlock()
+			r.cond.Broadcast()
+		})
+		defer remove()
+	}
+
+	holder = holder.self()
+
+	r.Lock()
+	defer r.Unlock()
+
+	sem, found := r.sems[name]
+	if !found {
+		sem = &semaphore{name: name, n: n, holders: make(map[Context]int)}
+		r.sems[name] = sem
+	} else if sem.n != n {
+		return nil, fmt.Errorf("semaphore %q requested with %d permits, already registered with %d", name, n, sem.n)
+	}
+
+	for sem.held >= sem.n {
+		if r.deadlocked(holder, sem) {
+			return nil, &DeadlockError{Name: name}
+		}
+
+		select {
+		case <-done:
+			return nil, ErrCanceled
+		default:
+		}
+
+		r.waiting[holder] = sem
+		r.cond.Wait()
+		delete(r.waiting, holder)
+	}
+
+	sem.held++
+	sem.holders[holder]++
+
+	return func() { r.release(holder, sem) }, nil
+}
+
+func (r *lockRegistry) release(holder Context, sem *semaphore) {
+	r.Lock()
+	sem.held--
+	if sem.holders[holder]--; sem.holders[holder] == 0 {
+		delete(sem.holders, holder)
+	}
+	r.Unlock()
+	r.cond.Broadcast()
+}
+
+// deadlocked reports whether every holder of sem is blocked on waiter, in which case waiting for sem would never
+// return. Must be called with the registry locked.
+func (r *lockRegistry) deadlocked(waiter Context, sem *semaphore) bool {
+	return r.stuck(waiter, sem, make(map[Context]bool))
+}
+
+// stuck reports whether all holders of sem are blocked on waiter.
+func (r *lockRegistry) stuck(waiter Context, sem *semaphore, visited map[Context]bool) bool {
+	for holder := range sem.holders {
+		if !r.blockedOn(holder, waiter, visited) {
+			return false
+		}
+	}
+	return true
+}
+
+// blockedOn reports whether holder cannot proceed until waiter does. A holder is blocked if it is waiter or one of its
+// ancestors (which wait on their parallel branches to complete), or if it or any of its descendants are waiting on a
+// semaphore whose holders are all blocked on waiter.
+func (r *lockRegistry) blockedOn(holder, waiter Context, visited map[Context]bool) bool {
+	if isAncestorOrSelf(holder, waiter) {
+		return true
+	}
+
+	if visited[holder] {
+		return false
+	}
+	visited[holder] = true
+
+	for w, sem := range r.waiting {
+		if isAncestorOrSelf(holder, w) && r.stuck(waiter, sem, visited) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isAncestorOrSelf(ancestor, ctx Context) bool {
+	for ctx != nil {
+		if ctx == ancestor {
+			return true
+		}
+
+		sc, ok := ctx.(*subCtx)
+		if !ok {
+			return false
+		}
+		ctx = sc.parent.self()
+	}
+	return false
+}
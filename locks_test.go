@@ -0,0 +1,222 @@
+package runner
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type concurrencyCommand struct {
+	active, max *int32
+}
+
+func (c *concurrencyCommand) Run(ctx Context, p Printer) {
+	n := atomic.AddInt32(c.active, 1)
+	for {
+		m := atomic.LoadInt32(c.max)
+		if n <= m || atomic.CompareAndSwapInt32(c.max, m, n) {
+			break
+		}
+	}
+	time.Sleep(10 * time.Millisecond)
+	atomic.AddInt32(c.active, -1)
+}
+
+func (c *concurrencyCommand) Rollback(ctx Context, p Printer) {
+	c.Run(ctx, p)
+}
+
+func TestLocked_Interfaces(t *testing.T) {
+	t.Parallel()
+
+	var (
+		l *locked
+		_ Command      = l
+		_ Rollbacker   = l
+		_ DryRunner    = l
+		_ fmt.Stringer = l
+	)
+}
+
+func TestLocked_String(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	cmd := &MockCommand{name: "foo"}
+
+	str := fmt.Sprint(WithLock("bar", cmd))
+	is.Contains(str, fmt.Sprint(cmd))
+	is.Contains(str, "lock bar")
+
+	str = fmt.Sprint(WithSemaphore("baz", 3, cmd))
+	is.Contains(str, "semaphore baz/3")
+}
+
+func TestLocked_Run_MutualExclusion(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	var active, max int32
+
+	cmds := make([]Command, 5)
+	for i := range cmds {
+		cmds[i] = WithLock("foo", &concurrencyCommand{&active, &max})
+	}
+
+	is.NoError(Run(MakeParallel(cmds...)))
+	is.Equal(int32(1), max)
+}
+
+func TestLocked_Run_Semaphore(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	var active, max int32
+
+	cmds := make([]Command, 6)
+	for i := range cmds {
+		cmds[i] = WithSemaphore("foo", 2, &concurrencyCommand{&active, &max})
+	}
+
+	is.NoError(Run(MakeParallel(MakeParallel(cmds[:3]...), MakeParallel(cmds[3:]...))))
+	is.Equal(int32(2), max, "semaphores should be honoured across nested parallel commands")
+}
+
+func TestLocked_Run_Sequential(t *testing.T) {
+	t.Parallel()
+
+	cmdA := &MockCommand{name: "A"}
+	cmdB := &MockCommand{name: "B"}
+
+	assert.NoError(t, Run(WithLock("foo", cmdA), WithLock("foo", cmdB)))
+}
+
+func TestLocked_Run_MismatchedPermits(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	cmd := &MockCommand{name: "B"}
+
+	err := Run(WithSemaphore("foo", 2, WithSemaphore("foo", 3, cmd)))
+	is.Error(err)
+	is.False(cmd.ran)
+}
+
+func TestLocked_Run_Reentrant(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	cmd := &MockCommand{name: "A"}
+
+	err := Run(WithLock("foo", NewSequence(WithLock("foo", cmd))))
+	is.Equal(&DeadlockError{Name: "foo"}, err)
+	is.False(cmd.ran)
+
+	err = Run(WithLock("foo", MakeParallel(WithLock("foo", cmd))))
+	is.Equal(&DeadlockError{Name: "foo"}, err, "locks held by ancestors should deadlock")
+	is.False(cmd.ran)
+
+	is.NoError(Run(WithSemaphore("foo", 2, WithSemaphore("foo", 2, cmd))))
+	is.True(cmd.ran)
+}
+
+func TestLocked_Run_Deadlock(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+
+	cmdA := &MockCommand{name: "A"}
+	cmdB := &MockCommand{name: "B"}
+
+	done := make(chan error)
+	go func() {
+		done <- Run(MakeParallel(
+			WithLock("foo", NewSequence(&MockCommand{delay: 20 * time.Millisecond}, WithLock("bar", cmdA))),
+			WithLock("bar", NewSequence(&MockCommand{delay: 20 * time.Millisecond}, WithLock("foo", cmdB))),
+		))
+	}()
+
+	select {
+	case err := <-done:
+		is.IsType(&DeadlockError{}, err)
+		is.Contains(err.Error(), "deadlock")
+		is.True(cmdA.ran != cmdB.ran, "exactly one branch should proceed")
+	case <-time.After(5 * time.Second):
+		is.Fail("deadlock was not detected")
+	}
+}
+
+func TestLocked_Rollback(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	var active, max int32
+
+	cmds := make([]Command, 4)
+	for i := range cmds {
+		cmds[i] = WithLock("foo", &concurrencyCommand{&active, &max})
+	}
+	cmds[3] = &MockCommand{name: "fail", err: errMockCanceled}
+
+	is.Error(Run(MakeParallel(cmds...)))
+	is.Equal(int32(1), max, "locks should be honoured during rollback")
+}
+
+func TestLocked_Rollback_Deadlock(t *testing.T) {
+	t.Parallel()
+
+	cmd := &MockCommand{name: "A"}
+	ctx := NewContext()
+	release, _ := ctx.locks().acquire(ctx, "foo", 1, true)
+	defer release()
+
+	WithLock("foo", cmd).(Rollbacker).Rollback(ctx, DefaultPrinter)
+	assert.False(t, cmd.rolledBack)
+}
+
+func TestLocked_DryRun(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	cmd := &MockCommand{name: "A"}
+
+	DryRun(WithLock("foo", cmd))
+	is.True(cmd.dryRan)
+
+	ctx := NewContext()
+	WithLock("foo", WithLock("foo", cmd)).(DryRunner).DryRun(ctx, DefaultPrinter)
+	is.IsType(&DeadlockError{}, ctx.Err())
+}
+
+func TestLocked_Run_Canceled(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	root := NewContext()
+
+	holder := commandFunc(func(ctx Context, p Printer) {
+		time.Sleep(20 * time.Millisecond)
+		root.cancel()
+		<-ctx.Done()
+		time.Sleep(50 * time.Millisecond)
+	})
+	waiter := &MockCommand{name: "B"}
+
+	delay := commandFunc(func(ctx Context, p Printer) { time.Sleep(5 * time.Millisecond) })
+
+	MakeParallel(WithLock("foo", holder), NewSequence(delay, WithLock("foo", waiter))).Run(root, DefaultPrinter)
+
+	is.Equal(ErrCanceled, root.Err())
+	is.False(waiter.ran, "waiters should stop waiting once canceled")
+}
+
+func TestWithSemaphore_InvalidPermits(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	is.Panics(func() { WithSemaphore("foo", 0, &MockCommand{}) })
+	is.Panics(func() { WithSemaphore("foo", -1, &MockCommand{}) })
+}
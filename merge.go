@@ -0,0 +1,70 @@
+package runner
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// A BranchValue is the value of a promoted key from a single parallel Command. Found is false if that Command did not
+// set the key (or failed, in the case of MakeQuorum).
+type BranchValue struct {
+	Value interface{}
+	Found bool
+}
+
+// A MergeFunc combines the values of a promoted key from each parallel Command into the single value set on the parent
+// Context. The vals slice is indexed in the order the Commands were passed to MakeParallel; at least one will be
+// found. Returning an error fails the parallel Command. See ParallelCommand.Promote.
+type MergeFunc func(key interface{}, vals []BranchValue) (merged interface{}, err error)
+
+// MergeConflictError is returned by MergeErrorOnConflict when parallel Commands set different values for a key.
+type MergeConflictError struct {
+	Key    interface{}
+	Values []BranchValue
+}
+
+func (e *MergeConflictError) Error() string {
+	return fmt.Sprintf("conflicting values set for key %v by parallel commands", e.Key)
+}
+
+// MergeLastWins is a MergeFunc that promotes the value from the last Command (in the order provided) that set the key.
+func MergeLastWins(key interface{}, vals []BranchValue) (interface{}, error) {
+	for i := len(vals) - 1; i >= 0; i-- {
+		if vals[i].Found {
+			return vals[i].Value, nil
+		}
+	}
+	return nil, nil
+}
+
+// MergeErrorOnConflict is a MergeFunc that promotes the value for the key if every Command that set it agrees on its
+// value (according to reflect.DeepEqual). Otherwise, a *MergeConflictError is returned.
+func MergeErrorOnConflict(key interface{}, vals []BranchValue) (interface{}, error) {
+	var (
+		merged interface{}
+		found  bool
+	)
+
+	for _, v := range vals {
+		if !v.Found {
+			continue
+		}
+
+		if found && !reflect.DeepEqual(merged, v.Value) {
+			return nil, &MergeConflictError{Key: key, Values: vals}
+		}
+		merged, found = v.Value, true
+	}
+
+	return merged, nil
+}
+
+// MergeCollect is a MergeFunc that promotes a []interface{} containing the value from each Command, indexed in the
+// order the Commands were provided. Commands that did not set the key have a nil entry.
+func MergeCollect(key interface{}, vals []BranchValue) (interface{}, error) {
+	merged := make([]interface{}, len(vals))
+	for i, v := range vals {
+		merged[i] = v.Value
+	}
+	return merged, nil
+}
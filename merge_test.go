@@ -0,0 +1,38 @@
+package runner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeLastWins(t *testing.T) {
+	t.Parallel()
+
+	val, err := MergeLastWins("foo", []BranchValue{{"a", true}, {"b", true}, {nil, false}})
+	assert.NoError(t, err)
+	assert.Equal(t, "b", val)
+}
+
+func TestMergeErrorOnConflict(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+
+	val, err := MergeErrorOnConflict("foo", []BranchValue{{nil, false}, {"a", true}, {"a", true}})
+	is.NoError(err)
+	is.Equal("a", val)
+
+	vals := []BranchValue{{"a", true}, {nil, false}, {"b", true}}
+	_, err = MergeErrorOnConflict("foo", vals)
+	is.Equal(&MergeConflictError{Key: "foo", Values: vals}, err)
+	is.Contains(err.Error(), "foo")
+}
+
+func TestMergeCollect(t *testing.T) {
+	t.Parallel()
+
+	val, err := MergeCollect("foo", []BranchValue{{"a", true}, {nil, false}, {"c", true}})
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"a", nil, "c"}, val)
+}
@@ -1,6 +1,14 @@
 package runner
 
-import "fmt"
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var errMockCanceled = errors.New("MOCK canceled")
 
 type MockCommand struct {
 	name string
@@ -9,12 +17,16 @@ type MockCommand struct {
 	set string
 	see string
 
+	delay        time.Duration
+	ignoreCancel bool
+
 	setVal     bool
 	seenVal    bool
 	ran        bool
 	failed     bool
 	rolledBack bool
 	dryRan     bool
+	canceled   bool
 }
 
 func (c *MockCommand) String() string {
@@ -24,6 +36,9 @@ func (c *MockCommand) String() string {
 func (c *MockCommand) Run(ctx Context, p Printer) {
 	p.Info("MOCK running %s", c.name)
 	c.ran = true
+	if c.maybeWait(ctx, p) {
+		return
+	}
 	c.maybeSetVal(ctx, p)
 	c.maybeSeeVal(ctx, p)
 	c.maybeFail(ctx, p)
@@ -38,6 +53,9 @@ func (c *MockCommand) Rollback(ctx Context, p Printer) {
 func (c *MockCommand) DryRun(ctx Context, p Printer) {
 	p.Info("MOCK dry run %s", c.name)
 	c.dryRan = true
+	if c.maybeWait(ctx, p) {
+		return
+	}
 	c.maybeSetVal(ctx, p)
 	c.maybeSeeVal(ctx, p)
 	c.maybeFail(ctx, p)
@@ -66,3 +84,47 @@ func (c *MockCommand) maybeSeeVal(ctx Context, p Printer) {
 		}
 	}
 }
+
+func (c *MockCommand) maybeWait(ctx Context, p Printer) (canceled bool) {
+	if c.delay == 0 {
+		return false
+	}
+
+	if c.ignoreCancel {
+		time.Sleep(c.delay)
+		return false
+	}
+
+	select {
+	case <-time.After(c.delay):
+		return false
+	case <-ctx.Done():
+		p.Warn("MOCK canceled %s", c.name)
+		c.canceled = true
+		ctx.SetErr(errMockCanceled)
+		return true
+	}
+}
+
+type commandFunc func(Context, Printer)
+
+func (fn commandFunc) Run(ctx Context, p Printer) {
+	fn(ctx, p)
+}
+
+type syncBuffer struct {
+	sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.Lock()
+	defer b.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.Lock()
+	defer b.Unlock()
+	return b.buf.String()
+}
@@ -0,0 +1,83 @@
+package runner
+
+// MultiPrinter returns a Printer that writes every message to each of the provided printers, similar to
+// io.MultiWriter. Each Printer applies its own LogLevel, so messages are only suppressed for the destinations whose
+// level excludes them. Printers derived via WithPrefix and AddFields apply the prefix or Fields to every destination.
+func MultiPrinter(printers ...Printer) Printer {
+	return multiPrinter(append([]Printer(nil), printers...))
+}
+
+type multiPrinter []Printer
+
+func (mp multiPrinter) Log(level LogLevel, format string, values ...interface{}) {
+	for _, p := range mp {
+		p.Log(level, format, values...)
+	}
+}
+
+func (mp multiPrinter) LogFields(level LogLevel, fields Fields, format string, values ...interface{}) {
+	for _, p := range mp {
+		if fp, ok := p.(FieldPrinter); ok {
+			fp.LogFields(level, fields, format, values...)
+		} else {
+			AddFields(p, fields).Log(level, format, values...)
+		}
+	}
+}
+
+func (mp multiPrinter) Trace(format string, values ...interface{}) {
+	mp.Log(LevelTrace, format, values...)
+}
+
+func (mp multiPrinter) Debug(format string, values ...interface{}) {
+	mp.Log(LevelDebug, format, values...)
+}
+
+func (mp multiPrinter) Info(format string, values ...interface{}) {
+	mp.Log(LevelInfo, format, values...)
+}
+
+func (mp multiPrinter) Warn(format string, values ...interface{}) {
+	mp.Log(LevelWarn, format, values...)
+}
+
+func (mp multiPrinter) Err(format string, values ...interface{}) {
+	mp.Log(LevelError, format, values...)
+}
+
+func (mp multiPrinter) Fatal(format string, values ...interface{}) {
+	mp.Log(LevelFatal, format, values...)
+}
+
+func (mp multiPrinter) WithPrefix(prefix string) Printer {
+	out := make(multiPrinter, len(mp))
+	for i, p := range mp {
+		out[i] = p.WithPrefix(prefix)
+	}
+	return out
+}
+
+func (mp multiPrinter) WithFields(fields Fields) Printer {
+	out := make(multiPrinter, len(mp))
+	for i, p := range mp {
+		out[i] = AddFields(p, fields)
+	}
+	return out
+}
+
+func (mp multiPrinter) withRunFields(fields Fields) Printer {
+	out := make(multiPrinter, len(mp))
+	for i, p := range mp {
+		out[i] = addRunFields(p, fields)
+	}
+	return out
+}
+
+func (mp multiPrinter) enabled(level LogLevel) bool {
+	for _, p := range mp {
+		if printerEnabled(p, level) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,38 @@
+package runner
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMultiPrinter(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	console, file, alerts := &bytes.Buffer{}, &bytes.Buffer{}, &bytes.Buffer{}
+
+	p := MultiPrinter(
+		NewPrinter(console, LevelInfo),
+		NewPrinter(file, LevelTrace),
+		plainPrinter{NewPrinter(alerts, LevelError)},
+	)
+
+	child := AddFields(p.WithPrefix("foo: "), Fields{"k": "v"}).WithPrefix("bar: ")
+	child.Trace("trace")
+	child.Info("info")
+	child.Err("%s", "error")
+
+	is.Equal("foo: bar: info k=v\nfoo: bar: error k=v\n", console.String())
+	is.Equal("foo: bar: trace k=v\nfoo: bar: info k=v\nfoo: bar: error k=v\n", file.String())
+	is.Equal("foo: bar: error k=v\n", alerts.String(), "prefixes and fields should apply to every destination")
+}
+
+func TestMultiPrinter_Empty(t *testing.T) {
+	t.Parallel()
+
+	assert.NotPanics(t, func() {
+		MultiPrinter().WithPrefix("foo").Fatal("bar")
+	})
+}
@@ -0,0 +1,151 @@
+package runner
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// A Phase describes which method of a Command is being executed.
+type Phase int8
+
+// Phase constants correspond to the Run, Rollback, and DryRun methods of a Command, respectively.
+const (
+	PhaseRun Phase = iota
+	PhaseRollback
+	PhaseDryRun
+)
+
+func (ph Phase) String() string {
+	switch ph {
+	case PhaseRun:
+		return "run"
+	case PhaseRollback:
+		return "rollback"
+	case PhaseDryRun:
+		return "dry-run"
+	}
+	return fmt.Sprintf("Phase(%d)", ph)
+}
+
+// An EventKind distinguishes the start of a Command's execution from its completion.
+type EventKind int8
+
+// EventKind constants are emitted before and after each Command executes, respectively.
+const (
+	EventStart EventKind = iota
+	EventFinish
+)
+
+func (k EventKind) String() string {
+	if k == EventStart {
+		return "start"
+	}
+	return "finish"
+}
+
+// An Event describes a Command starting or finishing execution. Events are emitted for each Command within sequences
+// and parallel Commands, including nested ones.
+type Event struct {
+	Kind  EventKind
+	Phase Phase
+
+	// Command is the Command being executed.
+	Command interface{}
+
+	// Path is the path of the Command from the outermost Command inward, as in Provenance.
+	Path []string
+
+	// cmds are the Commands named by Path, which identify them when their names are not unique.
+	cmds []interface{}
+
+	// Err is the Context's error when the Command finished. It is always nil for EventStart events and for rollbacks.
+	Err error
+
+	Time time.Time
+}
+
+// An Observer is notified of every Event in a run. Observers are called synchronously from the executing Command's
+// goroutine, and may be called concurrently by parallel Commands.
+type Observer interface {
+	Observe(e Event)
+}
+
+// ObserverFunc adapts a function to the Observer interface.
+type ObserverFunc func(e Event)
+
+// Observe calls fn(e).
+func (fn ObserverFunc) Observe(e Event) {
+	fn(e)
+}
+
+// A Journal is an Observer that records every Event of a run, in the order they occurred. It is safe for concurrent
+// use.
+type Journal struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+// Observe appends e to the Journal.
+func (j *Journal) Observe(e Event) {
+	j.mu.Lock()
+	j.events = append(j.events, e)
+	j.mu.Unlock()
+}
+
+// Events returns a copy of the Events recorded by the Journal.
+func (j *Journal) Events() []Event {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return append([]Event(nil), j.events...)
+}
+
+// begin records that cmd has started executing within ctx and notifies any Observers. It returns p with the Fields
+// describing the execution attached (see addRunFields), which should be passed to cmd.
+func begin(ctx Context, cmd interface{}, phase Phase, p Printer) Printer {
+	ctx.enter(cmd)
+	path := ctx.path()
+
+	cmdPath := strings.Join(path, commandPathSep)
+	fields := Fields{
+		FieldCommand: cmdPath,
+		FieldPhase:   phase.String(),
+	}
+	if phase == PhaseRun {
+		fields[FieldAttempt] = ctx.attempt(cmd, cmdPath)
+	}
+
+	ctx.notify(Event{
+		Kind:    EventStart,
+		Phase:   phase,
+		Command: cmd,
+		Path:    path,
+		cmds:    ctx.trace(),
+		Time:    time.Now(),
+	})
+
+	return addRunFields(p, fields)
+}
+
+// end records that cmd has finished executing within ctx and notifies any Observers. Failed runs are counted towards the
+// next attempt of cmd.
+func end(ctx Context, cmd interface{}, phase Phase) {
+	e := Event{
+		Kind:    EventFinish,
+		Phase:   phase,
+		Command: cmd,
+		Path:    ctx.path(),
+		cmds:    ctx.trace(),
+		Time:    time.Now(),
+	}
+	if phase != PhaseRollback {
+		e.Err = ctx.Err()
+	}
+	if phase == PhaseRun {
+		ctx.attempted(cmd, strings.Join(e.Path, commandPathSep), e.Err != nil)
+	}
+
+	ctx.leave()
+	ctx.notify(e)
+}
@@ -0,0 +1,156 @@
+package runner
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func summarize(events []Event) []string {
+	out := make([]string, len(events))
+	for i, e := range events {
+		out[i] = fmt.Sprintf("%v %v %v", e.Phase, e.Kind, e.Command)
+	}
+	return out
+}
+
+func TestPhase_String(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	is.Equal("run", PhaseRun.String())
+	is.Equal("rollback", PhaseRollback.String())
+	is.Equal("dry-run", PhaseDryRun.String())
+	is.Equal("Phase(9)", Phase(9).String())
+}
+
+func TestJournal_Sequence(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	err := errors.New("foo")
+
+	j := &Journal{}
+	is.Equal(err, Execute([]Command{
+		&MockCommand{name: "A"},
+		&MockCommand{name: "B", err: err},
+	}, WithJournal(j)))
+
+	events := j.Events()
+	is.Equal([]string{
+		"run start MOCK A",
+		"run finish MOCK A",
+		"run start MOCK B",
+		"run finish MOCK B",
+		"rollback start MOCK A",
+		"rollback finish MOCK A",
+	}, summarize(events))
+
+	is.NoError(events[1].Err)
+	is.Equal(err, events[3].Err)
+	is.Equal([]string{"MOCK B"}, events[2].Path)
+	for _, e := range events {
+		is.False(e.Time.IsZero())
+	}
+}
+
+func TestJournal_Parallel(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+
+	cmd := &MockCommand{name: "A"}
+	par := MakeParallel(cmd)
+
+	var paths [][]string
+	is.NoError(Execute([]Command{par}, WithDryRun(), WithObserver(ObserverFunc(func(e Event) {
+		if e.Kind == EventStart {
+			is.Equal(PhaseDryRun, e.Phase)
+			paths = append(paths, e.Path)
+		}
+	}))))
+
+	is.Equal([][]string{
+		{par.(fmt.Stringer).String()},
+		{par.(fmt.Stringer).String(), "MOCK A"},
+	}, paths)
+}
+
+func TestBegin_Fields(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+
+	fields := func(buf *bytes.Buffer) (out []map[string]interface{}) {
+		for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+			var rec struct {
+				Msg    string
+				Fields map[string]interface{}
+			}
+			is.NoError(json.Unmarshal([]byte(line), &rec))
+			if !strings.HasPrefix(rec.Msg, "MOCK running") && !strings.HasPrefix(rec.Msg, "MOCK dry run") {
+				continue
+			}
+			out = append(out, map[string]interface{}{
+				FieldCommand: rec.Fields[FieldCommand],
+				FieldPhase:   rec.Fields[FieldPhase],
+				FieldAttempt: rec.Fields[FieldAttempt],
+			})
+		}
+		return out
+	}
+
+	p, buf := getJSONTestPrinter(LevelInfo)
+	cmd := &MockCommand{name: "A"}
+	is.NoError(RunWithPrinter(p, cmd, cmd))
+	is.Equal([]map[string]interface{}{
+		{FieldCommand: "MOCK A", FieldPhase: "run", FieldAttempt: 1.0},
+		{FieldCommand: "MOCK A", FieldPhase: "run", FieldAttempt: 1.0},
+	}, fields(buf), "running the same Command twice is not a retry")
+
+	p, buf = getJSONTestPrinter(LevelInfo)
+	failing := &MockCommand{name: "B", err: errors.New("foo")}
+	is.Error(RunWithPrinter(p, MakeFailable(NewSequence(failing)), failing))
+	var attempts []interface{}
+	for _, f := range fields(buf) {
+		if f[FieldPhase] == "run" && strings.HasSuffix(f[FieldCommand].(string), "MOCK B") {
+			attempts = append(attempts, f[FieldAttempt])
+		}
+	}
+	is.Equal([]interface{}{1.0, 2.0}, attempts, "running a Command again after it failed is a retry")
+
+	p, buf = getJSONTestPrinter(LevelInfo)
+	DryRunWithPrinter(p, MakeParallel(cmd))
+	is.Equal([]map[string]interface{}{
+		{FieldCommand: "1 Parallel Commands > MOCK A", FieldPhase: "dry-run", FieldAttempt: nil},
+	}, fields(buf))
+}
+
+func TestBegin_Fields_Plain(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	buf := &syncBuffer{}
+
+	cmd := &MockCommand{name: "A"}
+	is.NoError(RunWithPrinter(NewPrinter(buf, LevelInfo), cmd))
+	is.Equal("MOCK running A\n", buf.String(), "automatic fields should not be written by NewPrinter")
+
+	buf = &syncBuffer{}
+	is.NoError(RunWithPrinter(AddFields(NewPrinter(buf, LevelInfo), Fields{"foo": "bar"}), cmd))
+	is.Equal("MOCK running A foo=bar\n", buf.String(), "explicit fields should still be written")
+}
+
+func TestCommandName(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	is.Equal("MOCK A", commandName(&MockCommand{name: "A"}))
+	is.Equal("foo", commandName("foo"))
+	is.Equal("runner.commandFunc", commandName(commandFunc(func(Context, Printer) {})))
+}
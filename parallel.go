@@ -2,13 +2,15 @@ package runner
 
 import (
 	"fmt"
-	"math/rand"
 	"sync"
+
+	"github.com/rodaine/runner/internal/namespace"
 )
 
 // MakeParallel returns a Command that executes the passed in cmds in parallel, threading the parent context into each
 // independently. Parallel Commands only share Context before forking; neither errors or key-value pairs are shared
-// between parallel Commands.
+// between parallel Commands. Selected key-value pairs can be copied into the parent Context once all parallel Commands
+// succeed via Promote.
 //
 // After all parallel Commands complete execution, if any Command failed, all other successful parallel Commands are
 // rolled back. A rollback initiated from a downstream command will also trigger rollbacks on each successful parallel
@@ -18,16 +20,34 @@ import (
 // run, respectively.
 //
 // This command implements the Rollbacker and DryRunner interfaces.
-func MakeParallel(cmds ...Command) Command {
+func MakeParallel(cmds ...Command) ParallelCommand {
 	return &parallel{
-		id:   fmt.Sprintf("parallel%d", rand.Int()),
+		key:  namespace.New("parallel").Key("contexts"),
 		cmds: cmds,
 	}
 }
 
+// A ParallelCommand describes the optional configuration methods available on the Command returned by MakeParallel.
+// These methods mutate the underlying Command; the Command is passed through for chaining convenience.
+type ParallelCommand interface {
+	Command
+
+	// Promote copies the values for keys set by the parallel Commands into the parent Context after all of them succeed,
+	// making them visible to downstream Commands. The values from each Command are combined with merge; keys not set by
+	// any Command are skipped. If merge returns an error, the parallel Commands are rolled back and the error is set on
+	// the parent Context.
+	Promote(merge MergeFunc, keys ...interface{}) ParallelCommand
+}
+
 type parallel struct {
-	id   string
-	cmds []Command
+	key        interface{}
+	cmds       []Command
+	promotions []promotion
+}
+
+type promotion struct {
+	key   interface{}
+	merge MergeFunc
 }
 
 func (c *parallel) String() string {
@@ -36,7 +56,7 @@ func (c *parallel) String() string {
 
 func (c *parallel) Run(ctx Context, p Printer) {
 	sctx := c.makeSubContexts(ctx)
-	ctx.Set(c.id, sctx)
+	ctx.Set(c.key, sctx)
 
 	wg := sync.WaitGroup{}
 	wg.Add(len(c.cmds))
@@ -46,6 +66,7 @@ func (c *parallel) Run(ctx Context, p Printer) {
 	}
 
 	wg.Wait()
+	detachSubContexts(sctx)
 
 	var err error
 	for i := range sctx {
@@ -55,7 +76,10 @@ func (c *parallel) Run(ctx Context, p Printer) {
 	}
 
 	if err == nil {
-		return
+		if err = c.promote(ctx, sctx); err == nil {
+			return
+		}
+		p.Err("%v", err)
 	}
 
 	c.Rollback(ctx, p)
@@ -64,7 +88,7 @@ func (c *parallel) Run(ctx Context, p Printer) {
 
 func (c *parallel) Rollback(ctx Context, p Printer) {
 	var sctx []Context
-	val, ok := ctx.Get(c.id)
+	val, ok := ctx.Get(c.key)
 	if sctx, ok = val.([]Context); !ok {
 		panic("contexts for parallel tasks missing")
 	}
@@ -81,7 +105,7 @@ func (c *parallel) Rollback(ctx Context, p Printer) {
 
 func (c *parallel) DryRun(ctx Context, p Printer) {
 	sctx := c.makeSubContexts(ctx)
-	ctx.Set(c.id, sctx)
+	ctx.Set(c.key, sctx)
 
 	wg := sync.WaitGroup{}
 	wg.Add(len(c.cmds))
@@ -91,6 +115,7 @@ func (c *parallel) DryRun(ctx Context, p Printer) {
 	}
 
 	wg.Wait()
+	detachSubContexts(sctx)
 
 	var err error
 	for i := range sctx {
@@ -99,24 +124,80 @@ func (c *parallel) DryRun(ctx Context, p Printer) {
 		}
 	}
 
+	if err == nil {
+		err = c.promote(ctx, sctx)
+	}
+
 	ctx.SetErr(err)
 }
 
+func (c *parallel) Promote(merge MergeFunc, keys ...interface{}) ParallelCommand {
+	for _, key := range keys {
+		c.promotions = append(c.promotions, promotion{key: key, merge: merge})
+	}
+	return c
+}
+
+// promote merges the promoted keys from each successful sub-context into the parent Context.
+func (c *parallel) promote(ctx Context, sctx []Context) error {
+	if len(c.promotions) == 0 {
+		return nil
+	}
+
+	locals := make([]hash, len(sctx))
+	for i := range sctx {
+		if sctx[i].Err() == nil {
+			locals[i] = sctx[i].locals()
+		}
+	}
+
+	for _, pr := range c.promotions {
+		vals := make([]BranchValue, len(sctx))
+		found := false
+		for i := range locals {
+			vals[i].Value, vals[i].Found = locals[i][pr.key]
+			found = found || vals[i].Found
+		}
+
+		if !found {
+			continue
+		}
+
+		val, err := pr.merge(pr.key, vals)
+		if err != nil {
+			return err
+		}
+		ctx.Set(pr.key, val)
+	}
+
+	return nil
+}
+
 func (c *parallel) runParallelCommand(cmd Command, ctx Context, p Printer, wg *sync.WaitGroup) {
-	cmd.Run(ctx, p)
+	bp, flush := branchPrinter(p, cmd)
+	cp := begin(ctx, cmd, PhaseRun, bp)
+	cmd.Run(ctx, cp)
+	end(ctx, cmd, PhaseRun)
+	flush()
 	wg.Done()
 }
 
 func (c *parallel) rollbackParallelCommand(cmd Command, ctx Context, p Printer, wg *sync.WaitGroup) {
 	if rb, ok := cmd.(Rollbacker); ok && ctx.Err() == nil {
-		rb.Rollback(ctx, p)
+		bp, flush := branchPrinter(p, cmd)
+		rollback(ctx, rb, bp)
+		flush()
 	}
 	wg.Done()
 }
 
 func (c *parallel) dryRunParallelCommand(cmd Command, ctx Context, p Printer, wg *sync.WaitGroup) {
 	if dr, ok := cmd.(DryRunner); ok {
-		dr.DryRun(ctx, p)
+		bp, flush := branchPrinter(p, cmd)
+		cp := begin(ctx, cmd, PhaseDryRun, bp)
+		dr.DryRun(ctx, cp)
+		end(ctx, cmd, PhaseDryRun)
+		flush()
 	}
 	wg.Done()
 }
@@ -214,3 +214,91 @@ func TestParallel_InFailingSequence(t *testing.T) {
 		is.True(cmd.rolledBack)
 	}
 }
+
+func TestParallel_Promote(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+
+	ctx := NewContext()
+	ctx.Set("foo", "parent")
+
+	MakeParallel(
+		&MockCommand{name: "A", set: "foo"},
+		&MockCommand{name: "B"},
+		&MockCommand{name: "C", set: "foo"},
+	).Promote(MergeLastWins, "foo").Promote(MergeCollect, "bar").Run(ctx, DefaultPrinter)
+
+	is.NoError(ctx.Err())
+
+	val, _ := ctx.Get("foo")
+	is.Equal("C", val)
+
+	_, found := ctx.Get("bar")
+	is.False(found, "keys not set by any command should not be promoted")
+}
+
+func TestParallel_Promote_Conflict(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+
+	cmdA := &MockCommand{name: "A", set: "foo"}
+	cmdB := &MockCommand{name: "B", set: "foo"}
+
+	ctx := NewContext()
+	MakeParallel(cmdA, cmdB).Promote(MergeErrorOnConflict, "foo").Run(ctx, DefaultPrinter)
+
+	is.IsType(&MergeConflictError{}, ctx.Err())
+	is.True(cmdA.rolledBack)
+	is.True(cmdB.rolledBack)
+
+	_, found := ctx.Get("foo")
+	is.False(found)
+}
+
+func TestParallel_Promote_DryRun(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+
+	ctx := NewContext()
+	MakeParallel(
+		&MockCommand{name: "A", set: "foo"},
+		&MockCommand{name: "B", set: "foo"},
+	).Promote(MergeCollect, "foo").(DryRunner).DryRun(ctx, DefaultPrinter)
+
+	is.NoError(ctx.Err())
+	val, _ := ctx.Get("foo")
+	is.Equal([]interface{}{"A", "B"}, val)
+
+	ctx = NewContext()
+	MakeParallel(
+		&MockCommand{name: "A", set: "foo"},
+		&MockCommand{name: "B", set: "foo"},
+	).Promote(MergeErrorOnConflict, "foo").(DryRunner).DryRun(ctx, DefaultPrinter)
+	is.IsType(&MergeConflictError{}, ctx.Err())
+}
+
+func TestParallel_Promote_Custom(t *testing.T) {
+	t.Parallel()
+
+	sum := func(key interface{}, vals []BranchValue) (interface{}, error) {
+		total := 0
+		for _, v := range vals {
+			if v.Found {
+				total += v.Value.(int)
+			}
+		}
+		return total, nil
+	}
+
+	ctx := NewContext()
+	MakeParallel(
+		commandFunc(func(ctx Context, p Printer) { ctx.Set("n", 1) }),
+		commandFunc(func(ctx Context, p Printer) { ctx.Set("n", 2) }),
+	).Promote(sum, "n").Run(ctx, DefaultPrinter)
+
+	val, _ := ctx.Get("n")
+	assert.Equal(t, 3, val)
+}
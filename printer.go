@@ -4,6 +4,10 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 )
 
 // A LogLevel value describes the minimum logging verbosity for a Printer to output messages passed to it. Messages with
@@ -23,6 +27,28 @@ const (
 	LevelOff
 )
 
+func (l LogLevel) String() string {
+	switch l {
+	case LevelAll:
+		return "all"
+	case LevelTrace:
+		return "trace"
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	case LevelFatal:
+		return "fatal"
+	case LevelOff:
+		return "off"
+	}
+	return fmt.Sprintf("LogLevel(%d)", l)
+}
+
 // A Printer is passed into every command and should be used exclusively for logging the behavior of a command. Direct
 // use of the fmt or log packages is for maintaining the cleanliness of the output. All logging methods should emulate
 // fmt.Printf interpolation.
@@ -57,8 +83,89 @@ type Printer interface {
 	WithPrefix(prefix string) Printer
 }
 
+// Fields are structured key-value pairs attached to log messages, allowing them to be consumed by log aggregators
+// without parsing the formatted message.
+type Fields map[string]interface{}
+
+// Field names set automatically on the Printer passed to each Command executed within a sequence or parallel Command.
+// They are included by structured Printers such as NewJSONPrinter, but omitted by NewPrinter and the Printers based on
+// it, whose output only includes Fields attached explicitly with AddFields.
+const (
+	// FieldCommand is the path of the executing Command, from the outermost Command inward, as in Provenance.
+	FieldCommand = "command"
+
+	// FieldPhase is the Phase being executed, as a string.
+	FieldPhase = "phase"
+
+	// FieldAttempt is the number of times the Command has been attempted within the run, starting at 1. It is only
+	// incremented when the Command runs again after failing, such as when retried after being wrapped by
+	// MakeFailable, and is only set during PhaseRun.
+	FieldAttempt = "attempt"
+)
+
+// A FieldPrinter is a Printer that supports structured Fields. Fields attached via WithFields must be included in every
+// message logged by the returned Printer, as well as by any Printers derived from it via WithPrefix.
+type FieldPrinter interface {
+	Printer
+
+	// LogFields writes an arbitrary message with the given Fields at the given LogLevel. Like Log, it is exposed for
+	// Printer implementations that compose with other printers. Fields passed to LogFields take precedence over those
+	// attached to the Printer with the same name.
+	LogFields(level LogLevel, fields Fields, format string, values ...interface{})
+
+	// WithFields should return a new Printer that attaches the provided fields to all messages.
+	WithFields(fields Fields) Printer
+}
+
+// AddFields returns a Printer that attaches fields to all messages logged through p, taking precedence over any
+// existing fields with the same name. If p is not a FieldPrinter, the fields are instead appended to each message as
+// key=value pairs.
+func AddFields(p Printer, fields Fields) Printer {
+	if fp, ok := p.(FieldPrinter); ok {
+		return fp.WithFields(fields)
+	}
+	return &fieldsPrinter{Printer: p, fields: fields}
+}
+
+// runFieldPrinter is implemented by Printers that handle the Fields set automatically by the runner differently from
+// those attached explicitly.
+type runFieldPrinter interface {
+	withRunFields(fields Fields) Printer
+}
+
+// levelPrinter is implemented by Printers that can report whether messages at a LogLevel may be output, allowing
+// callers to skip building expensive messages that would be suppressed.
+type levelPrinter interface {
+	enabled(level LogLevel) bool
+}
+
+// printerEnabled reports whether p may output messages at level. Printers that do not implement levelPrinter are
+// assumed not to.
+func printerEnabled(p Printer, level LogLevel) bool {
+	lp, ok := p.(levelPrinter)
+	return ok && lp.enabled(level)
+}
+
+// addRunFields attaches the Fields set automatically by the runner to p. Unlike AddFields, Printers that do not
+// support Fields are returned unchanged.
+func addRunFields(p Printer, fields Fields) Printer {
+	switch p := p.(type) {
+	case runFieldPrinter:
+		return p.withRunFields(fields)
+	case FieldPrinter:
+		return p.WithFields(fields)
+	}
+	return p
+}
+
 // NewPrinter returns a standard Printer which writes all logs to the provided io.Writer. LogLevels below the provided
-// level are suppressed from output.
+// level are suppressed from output. Fields are appended to each message as key=value pairs, sorted by name.
+//
+// The Printer is safe for concurrent use: each message is written as a complete line with a single call to Write, and
+// writes are serialized across all Printers derived from it via WithPrefix and AddFields.
+//
+// The Fields set automatically by the runner, such as FieldCommand, are not included, so the output of Commands is the
+// same as if they were executed directly.
 func NewPrinter(w io.Writer, level LogLevel) Printer {
 	return &stdPrinter{
 		w:     w,
@@ -67,15 +174,23 @@ func NewPrinter(w io.Writer, level LogLevel) Printer {
 }
 
 type stdPrinter struct {
-	parent Printer
+	parent *stdPrinter
 	w      io.Writer
 	level  LogLevel
 	prefix string
+	fields Fields
+
+	// mu serializes writes to w; it is only used by the root Printer, which all children log through.
+	mu sync.Mutex
 }
 
 func (p *stdPrinter) Log(level LogLevel, format string, values ...interface{}) {
+	p.LogFields(level, nil, format, values...)
+}
+
+func (p *stdPrinter) LogFields(level LogLevel, fields Fields, format string, values ...interface{}) {
 	if p.parent != nil {
-		p.parent.Log(level, p.prefix+format, values...)
+		p.parent.LogFields(level, mergeFields(p.fields, fields), p.prefix+format, values...)
 		return
 	}
 
@@ -83,11 +198,11 @@ func (p *stdPrinter) Log(level LogLevel, format string, values ...interface{}) {
 		return
 	}
 
-	if len(values) == 0 {
-		fmt.Fprintln(p.w, format)
-	} else {
-		fmt.Fprintf(p.w, format+"\n", values...)
-	}
+	line := sprintf(format, values...) + formatFields(fields) + "\n"
+
+	p.mu.Lock()
+	_, _ = io.WriteString(p.w, line)
+	p.mu.Unlock()
 }
 
 func (p *stdPrinter) Trace(format string, values ...interface{}) {
@@ -121,5 +236,128 @@ func (p *stdPrinter) WithPrefix(prefix string) Printer {
 	}
 }
 
+func (p *stdPrinter) WithFields(fields Fields) Printer {
+	return &stdPrinter{
+		parent: p,
+		fields: fields,
+	}
+}
+
+func (p *stdPrinter) withRunFields(Fields) Printer {
+	return p
+}
+
+func (p *stdPrinter) enabled(level LogLevel) bool {
+	for p.parent != nil {
+		p = p.parent
+	}
+	return level >= p.level
+}
+
+// fieldsPrinter attaches Fields to a Printer that does not implement FieldPrinter by appending them to each message.
+type fieldsPrinter struct {
+	Printer
+	fields Fields
+}
+
+func (p *fieldsPrinter) Log(level LogLevel, format string, values ...interface{}) {
+	p.LogFields(level, nil, format, values...)
+}
+
+func (p *fieldsPrinter) LogFields(level LogLevel, fields Fields, format string, values ...interface{}) {
+	p.Printer.Log(level, "%s", sprintf(format, values...)+formatFields(mergeFields(p.fields, fields)))
+}
+
+func (p *fieldsPrinter) Trace(format string, values ...interface{}) {
+	p.Log(LevelTrace, format, values...)
+}
+
+func (p *fieldsPrinter) Debug(format string, values ...interface{}) {
+	p.Log(LevelDebug, format, values...)
+}
+
+func (p *fieldsPrinter) Info(format string, values ...interface{}) {
+	p.Log(LevelInfo, format, values...)
+}
+
+func (p *fieldsPrinter) Warn(format string, values ...interface{}) {
+	p.Log(LevelWarn, format, values...)
+}
+
+func (p *fieldsPrinter) Err(format string, values ...interface{}) {
+	p.Log(LevelError, format, values...)
+}
+
+func (p *fieldsPrinter) Fatal(format string, values ...interface{}) {
+	p.Log(LevelFatal, format, values...)
+}
+
+func (p *fieldsPrinter) WithPrefix(prefix string) Printer {
+	return &fieldsPrinter{Printer: p.Printer.WithPrefix(prefix), fields: p.fields}
+}
+
+func (p *fieldsPrinter) WithFields(fields Fields) Printer {
+	return &fieldsPrinter{Printer: p.Printer, fields: mergeFields(p.fields, fields)}
+}
+
+func (p *fieldsPrinter) withRunFields(Fields) Printer {
+	return p
+}
+
+func (p *fieldsPrinter) enabled(level LogLevel) bool {
+	return printerEnabled(p.Printer, level)
+}
+
+// sprintf formats the message like fmt.Sprintf, unless there are no values, in which case format is used verbatim.
+func sprintf(format string, values ...interface{}) string {
+	if len(values) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, values...)
+}
+
+// mergeFields returns the union of outer and inner, with inner taking precedence.
+func mergeFields(outer, inner Fields) Fields {
+	if len(outer) == 0 {
+		return inner
+	}
+	if len(inner) == 0 {
+		return outer
+	}
+
+	out := make(Fields, len(outer)+len(inner))
+	for k, v := range outer {
+		out[k] = v
+	}
+	for k, v := range inner {
+		out[k] = v
+	}
+	return out
+}
+
+// formatFields renders fields as space-prefixed key=value pairs sorted by key. Values containing spaces, quotes, or
+// equal signs are quoted.
+func formatFields(fields Fields) string {
+	if len(fields) == 0 {
+		return ""
+	}
+
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	for _, k := range keys {
+		v := fmt.Sprint(fields[k])
+		if v == "" || strings.ContainsAny(v, " =\"") {
+			v = strconv.Quote(v)
+		}
+		fmt.Fprintf(&sb, " %s=%s", k, v)
+	}
+	return sb.String()
+}
+
 // DefaultPrinter writes to os.stdOut at the Info LogLevel. It is the printer used by Run and DryRun.
 var DefaultPrinter = NewPrinter(os.Stdout, LevelInfo)
@@ -2,7 +2,10 @@ package runner
 
 import (
 	"bytes"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -83,3 +86,89 @@ func TestPrinter_WithPrefix(t *testing.T) {
 	prefixed.Info("bar")
 	assert.Equal(t, "foobar\n", out.String())
 }
+
+func TestPrinter_WithFields(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	p, out := getTestPrinter()
+
+	fp := AddFields(p, Fields{"foo": "bar", "fizz": 1})
+	fp.Info("alpha")
+	is.Equal("alpha fizz=1 foo=bar\n", out.String())
+	out.Reset()
+
+	fp.WithPrefix("pre: ").(FieldPrinter).LogFields(LevelInfo, Fields{"foo": "baz qux"}, "%s", "beta")
+	is.Equal("pre: beta fizz=1 foo=\"baz qux\"\n", out.String(), "fields should flow through prefixes")
+	out.Reset()
+
+	p.level = LevelWarn
+	fp.Info("gamma")
+	is.Empty(out.String(), "fields should not bypass the root level")
+}
+
+type plainPrinter struct {
+	Printer
+}
+
+func TestAddFields_Fallback(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	p, out := getTestPrinter()
+
+	fp := AddFields(plainPrinter{p}, Fields{"foo": "bar"})
+	_, ok := fp.(*fieldsPrinter)
+	is.True(ok)
+
+	fp.Warn("%d%%", 100)
+	is.Equal("100% foo=bar\n", out.String())
+	out.Reset()
+
+	AddFields(fp, Fields{"foo": "baz"}).WithPrefix("pre: ").Info("alpha")
+	is.Equal("pre: alpha foo=baz\n", out.String())
+}
+
+// tearingWriter fails the test if Write is called concurrently or with anything other than a single complete line.
+type tearingWriter struct {
+	t       *testing.T
+	writing int32
+	lines   int32
+}
+
+func (w *tearingWriter) Write(p []byte) (int, error) {
+	if !atomic.CompareAndSwapInt32(&w.writing, 0, 1) {
+		w.t.Error("concurrent write")
+	}
+	defer atomic.StoreInt32(&w.writing, 0)
+
+	if bytes.IndexByte(p, '\n') != len(p)-1 {
+		w.t.Errorf("write is not a single complete line: %q", p)
+	}
+	atomic.AddInt32(&w.lines, 1)
+
+	time.Sleep(time.Microsecond)
+	return len(p), nil
+}
+
+func TestPrinter_Concurrent(t *testing.T) {
+	t.Parallel()
+
+	w := &tearingWriter{t: t}
+	p := NewPrinter(w, LevelAll)
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			child := AddFields(p.WithPrefix("branch: "), Fields{"i": i})
+			for j := 0; j < 10; j++ {
+				child.Info("message %d", j)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	assert.EqualValues(t, 100, atomic.LoadInt32(&w.lines))
+}
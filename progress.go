@@ -0,0 +1,295 @@
+package runner
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// progressInterval is how often a Progress redraws to animate its spinners.
+const progressInterval = 100 * time.Millisecond
+
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// Progress is a Printer and Observer that displays a live tree of the Commands in a run. Running Commands are shown
+// with a spinner and finished ones with a check or cross mark, while Commands being rolled back are marked as such
+// along with an indicator that the run is rolling back. Only the Commands within running and failed Commands are
+// expanded: those nested within other finished Commands are collapsed into a single line counting them by outcome, so
+// completed parts of the run take up a line each. Log messages are written above the tree as by NewPrinter.
+//
+// The tree is only drawn if the output is a terminal, that is, a character device or a value with an IsTerminal()
+// bool method that returns true. Otherwise, Progress degrades to writing plain log messages. To use it, pass it as both
+// the Printer and an Observer of a run, such as with WithProgress. Close must be called once the run completes.
+type Progress struct {
+	FieldPrinter
+
+	mu  sync.Mutex
+	w   io.Writer
+	tty bool
+
+	root        *progressNode
+	nodes       map[progressKey]*progressNode
+	rollingBack bool
+
+	frame int
+	drawn int
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+type progressStatus int8
+
+const (
+	progressRunning progressStatus = iota
+	progressSucceeded
+	progressFailed
+	progressRollingBack
+	progressRolledBack
+)
+
+type progressNode struct {
+	name     string
+	status   progressStatus
+	children []*progressNode
+}
+
+// progressKey identifies a node by its parent and its Command (see commandID), so that Commands with the same name
+// have their own nodes.
+type progressKey struct {
+	parent *progressNode
+	cmd    interface{}
+}
+
+// NewProgress returns a Progress which writes to the provided io.Writer. Log messages with a LogLevel below the
+// provided level are suppressed from output.
+func NewProgress(w io.Writer, level LogLevel) *Progress {
+	pr := &Progress{
+		w:     w,
+		tty:   isTerminal(w),
+		root:  &progressNode{},
+		nodes: make(map[progressKey]*progressNode),
+		done:  make(chan struct{}),
+	}
+	pr.FieldPrinter = NewPrinter(progressWriter{pr}, level).(FieldPrinter)
+
+	if pr.tty {
+		go pr.animate()
+	}
+
+	return pr
+}
+
+// Observe updates the tree with the Event and redraws it.
+func (pr *Progress) Observe(e Event) {
+	pr.mu.Lock()
+	defer pr.mu.Unlock()
+
+	node := pr.node(e.Path, e.cmds)
+	switch {
+	case e.Phase == PhaseRollback && e.Kind == EventStart:
+		node.status = progressRollingBack
+		pr.rollingBack = true
+	case e.Phase == PhaseRollback:
+		node.status = progressRolledBack
+	case e.Kind == EventStart:
+		node.status = progressRunning
+	case e.Err != nil:
+		node.status = progressFailed
+	default:
+		node.status = progressSucceeded
+	}
+
+	pr.redraw(nil)
+}
+
+// Close stops animating the tree, leaving its final state drawn above any further output.
+func (pr *Progress) Close() error {
+	pr.closeOnce.Do(func() {
+		close(pr.done)
+
+		pr.mu.Lock()
+		pr.redraw(nil)
+		pr.drawn = 0
+		pr.mu.Unlock()
+	})
+	return nil
+}
+
+func (pr *Progress) withRunFields(Fields) Printer {
+	return pr
+}
+
+func (pr *Progress) enabled(level LogLevel) bool {
+	return printerEnabled(pr.FieldPrinter, level)
+}
+
+func (pr *Progress) animate() {
+	t := time.NewTicker(progressInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-t.C:
+			pr.mu.Lock()
+			pr.frame++
+			pr.redraw(nil)
+			pr.mu.Unlock()
+		case <-pr.done:
+			return
+		}
+	}
+}
+
+// node returns the node for the Commands cmds named by path, creating it and any missing ancestors. If cmds does not
+// match path, Commands are identified by name alone. Must be called with pr locked.
+func (pr *Progress) node(path []string, cmds []interface{}) *progressNode {
+	n := pr.root
+	for i, name := range path {
+		key := progressKey{parent: n, cmd: name}
+		if len(cmds) == len(path) {
+			key.cmd = commandID(cmds[i], name)
+		}
+
+		child, ok := pr.nodes[key]
+		if !ok {
+			child = &progressNode{name: name}
+			n.children = append(n.children, child)
+			pr.nodes[key] = child
+		}
+		n = child
+	}
+	return n
+}
+
+// redraw erases the tree, writes line (if any) in its place, and draws the tree below it. If the output is not a
+// terminal, only line is written. Must be called with pr locked.
+func (pr *Progress) redraw(line []byte) {
+	if !pr.tty {
+		_, _ = pr.w.Write(line)
+		return
+	}
+
+	buf := &bytes.Buffer{}
+	if pr.drawn > 0 {
+		fmt.Fprintf(buf, "\x1b[%dF\x1b[J", pr.drawn)
+	}
+	buf.Write(line)
+
+	lines := pr.render()
+	for _, l := range lines {
+		buf.WriteString(l)
+		buf.WriteByte('\n')
+	}
+	pr.drawn = len(lines)
+
+	_, _ = pr.w.Write(buf.Bytes())
+}
+
+// render returns the lines of the tree. The children of active and failed Commands are shown, as are the top-level
+// Commands, while the descendants of other finished Commands are collapsed into a summary line counting them by
+// outcome. Must be called with pr locked.
+func (pr *Progress) render() []string {
+	active := make(map[*progressNode]bool)
+	var mark func(n *progressNode) bool
+	mark = func(n *progressNode) bool {
+		isActive := n.status == progressRunning || n.status == progressRollingBack
+		for _, c := range n.children {
+			if mark(c) {
+				isActive = true
+			}
+		}
+		active[n] = isActive
+		return isActive
+	}
+	mark(pr.root)
+
+	var counts [progressRolledBack + 1]int
+	var count func(n *progressNode)
+	count = func(n *progressNode) {
+		for _, c := range n.children {
+			counts[c.status]++
+			count(c)
+		}
+	}
+
+	var tree []string
+	var walk func(n *progressNode, depth int)
+	walk = func(n *progressNode, depth int) {
+		for _, c := range n.children {
+			tree = append(tree, strings.Repeat("  ", depth)+pr.mark(c.status)+" "+c.name+pr.suffix(c.status))
+			if active[c] || c.status == progressFailed {
+				walk(c, depth+1)
+			} else {
+				count(c)
+			}
+		}
+	}
+	walk(pr.root, 0)
+
+	var lines []string
+	if pr.rollingBack {
+		lines = append(lines, "↺ rolling back")
+	}
+	if summary := pr.summary(counts[:]); summary != "" {
+		lines = append(lines, summary)
+	}
+	return append(lines, tree...)
+}
+
+// summary returns a line counting the collapsed Commands by status, or an empty string if there are none.
+func (pr *Progress) summary(counts []int) string {
+	var parts []string
+	for _, st := range []progressStatus{progressSucceeded, progressFailed, progressRolledBack} {
+		if counts[st] > 0 {
+			parts = append(parts, fmt.Sprintf("%s %d %s", pr.mark(st), counts[st], progressLabels[st]))
+		}
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "… " + strings.Join(parts, "  ")
+}
+
+var progressLabels = map[progressStatus]string{
+	progressSucceeded:  "succeeded",
+	progressFailed:     "failed",
+	progressRolledBack: "rolled back",
+}
+
+func (pr *Progress) mark(status progressStatus) string {
+	switch status {
+	case progressSucceeded:
+		return "✓"
+	case progressFailed:
+		return "✗"
+	case progressRolledBack:
+		return "↺"
+	}
+	return spinnerFrames[pr.frame%len(spinnerFrames)]
+}
+
+func (pr *Progress) suffix(status progressStatus) string {
+	switch status {
+	case progressRollingBack:
+		return " (rolling back)"
+	case progressRolledBack:
+		return " (rolled back)"
+	}
+	return ""
+}
+
+// progressWriter writes log lines through a Progress, above its tree.
+type progressWriter struct {
+	pr *Progress
+}
+
+func (w progressWriter) Write(p []byte) (int, error) {
+	w.pr.mu.Lock()
+	w.pr.redraw(p)
+	w.pr.mu.Unlock()
+	return len(p), nil
+}
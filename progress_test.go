@@ -0,0 +1,160 @@
+package runner
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeTTY is a terminal for testing Progress, recording everything written to it.
+type fakeTTY struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (t *fakeTTY) IsTerminal() bool { return true }
+
+func (t *fakeTTY) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.buf.Write(p)
+}
+
+func (t *fakeTTY) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.buf.String()
+}
+
+func TestProgress_TTY(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	tty := &fakeTTY{}
+	pr := NewProgress(tty, LevelWarn)
+	is.True(pr.tty)
+
+	err := errors.New("foo")
+	cmdA := &MockCommand{name: "A"}
+	par := MakeParallel(&MockCommand{name: "B"})
+
+	is.Equal(err, Execute([]Command{cmdA, par, &MockCommand{name: "D", err: err}}, WithProgress(pr)))
+	is.NoError(pr.Close())
+
+	pr.mu.Lock()
+	lines := pr.render()
+	pr.mu.Unlock()
+
+	is.Equal([]string{
+		"↺ rolling back",
+		"… ↺ 1 rolled back",
+		"↺ MOCK A (rolled back)",
+		"↺ 1 Parallel Commands (rolled back)",
+		"✗ MOCK D",
+	}, lines, "finished commands should be named, with their nested commands collapsed")
+
+	out := tty.String()
+	is.Contains(out, "\x1b[J", "the tree should be redrawn in place")
+	is.Regexp("J✓ MOCK A\n. 1 Parallel Commands\n  . MOCK B\n", out, "active groups should be shown with finished siblings")
+	is.Regexp("JMOCK error D: foo\n… ✓ 1 succeeded\n✓ MOCK A\n✓ 1 Parallel Commands\n. MOCK D\n", out,
+		"logs should be written above the tree")
+}
+
+func TestProgress_Plain(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	buf := &syncBuffer{}
+	pr := NewProgress(buf, LevelInfo)
+	defer pr.Close()
+	is.False(pr.tty)
+
+	is.NoError(Execute([]Command{&MockCommand{name: "A"}}, WithProgress(pr)))
+	is.Equal("MOCK running A\n", buf.String(), "non-terminals should only see logs")
+}
+
+func TestProgress_Marks(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	pr := NewProgress(&bytes.Buffer{}, LevelInfo)
+	defer pr.Close()
+
+	pr.Observe(Event{Kind: EventStart, Phase: PhaseDryRun, Path: []string{"A", "B"}})
+	pr.Observe(Event{Kind: EventStart, Phase: PhaseRollback, Path: []string{"C"}})
+
+	pr.mu.Lock()
+	defer pr.mu.Unlock()
+	is.Equal([]string{
+		"↺ rolling back",
+		"⠋ A",
+		"  ⠋ B",
+		"⠋ C (rolling back)",
+	}, pr.render(), "missing ancestors should be created")
+}
+
+func TestProgress_Identity(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	pr := NewProgress(&bytes.Buffer{}, LevelInfo)
+	defer pr.Close()
+
+	par := MakeParallel()
+	a1, a2 := &MockCommand{name: "A"}, &MockCommand{name: "A"}
+	path := []string{"P", "A"}
+
+	pr.Observe(Event{Kind: EventStart, Phase: PhaseRun, Path: path, cmds: []interface{}{par, a1}})
+	pr.Observe(Event{Kind: EventStart, Phase: PhaseRun, Path: path, cmds: []interface{}{par, a2}})
+	pr.Observe(Event{Kind: EventFinish, Phase: PhaseRun, Path: path, cmds: []interface{}{par, a1}})
+
+	pr.mu.Lock()
+	defer pr.mu.Unlock()
+	is.Equal([]string{
+		"⠋ P",
+		"  ✓ A",
+		"  ⠋ A",
+	}, pr.render(), "commands with the same name should have their own nodes")
+}
+
+func TestProgress_Collapse(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	pr := NewProgress(&bytes.Buffer{}, LevelInfo)
+	defer pr.Close()
+
+	for _, path := range [][]string{{"A"}, {"A", "B"}, {"A", "B", "C"}, {"D"}, {"D", "E"}, {"D", "F"}} {
+		pr.Observe(Event{Kind: EventStart, Phase: PhaseRun, Path: path})
+	}
+	pr.Observe(Event{Kind: EventFinish, Phase: PhaseRun, Path: []string{"A", "B", "C"}})
+	pr.Observe(Event{Kind: EventFinish, Phase: PhaseRun, Path: []string{"A", "B"}})
+	pr.Observe(Event{Kind: EventFinish, Phase: PhaseRun, Path: []string{"A"}})
+	pr.Observe(Event{Kind: EventFinish, Phase: PhaseRun, Path: []string{"D", "E"}, Err: errors.New("foo")})
+
+	pr.mu.Lock()
+	is.Equal([]string{
+		"… ✓ 2 succeeded",
+		"✓ A",
+		"⠋ D",
+		"  ✗ E",
+		"  ⠋ F",
+	}, pr.render(), "finished subtrees outside the active group should be collapsed")
+	pr.mu.Unlock()
+
+	pr.Observe(Event{Kind: EventFinish, Phase: PhaseRun, Path: []string{"D", "F"}})
+	pr.Observe(Event{Kind: EventFinish, Phase: PhaseRun, Path: []string{"D"}, Err: errors.New("foo")})
+
+	pr.mu.Lock()
+	is.Equal([]string{
+		"… ✓ 2 succeeded",
+		"✓ A",
+		"✗ D",
+		"  ✗ E",
+		"  ✓ F",
+	}, pr.render(), "failed commands should remain expanded")
+	pr.mu.Unlock()
+}
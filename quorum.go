@@ -0,0 +1,143 @@
+package runner
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/rodaine/runner/internal/namespace"
+)
+
+// MakeQuorum returns a Command that executes the passed in cmds in parallel, succeeding if at least n of them succeed.
+// Each Command receives its own forked Context, as with MakeParallel. Commands that fail are reported as warnings and
+// are not rolled back.
+//
+// If fewer than n Commands succeed, all successful Commands are rolled back and a QuorumError describing every failure
+// is set on the parent Context. A rollback initiated from a downstream Command rolls back each successful Command.
+//
+// Values promoted via Promote are merged from the successful Commands only; failed Commands appear as not found.
+//
+// MakeQuorum panics if n is not between 1 and the number of cmds, as the quorum could otherwise never fail or never
+// succeed.
+//
+// This command implements the Rollbacker and DryRunner interfaces.
+func MakeQuorum(n int, cmds ...Command) ParallelCommand {
+	if n < 1 || n > len(cmds) {
+		panic(fmt.Sprintf("quorum of %d is invalid for %d commands: must be between 1 and %d", n, len(cmds), len(cmds)))
+	}
+
+	return &quorum{
+		parallel: &parallel{
+			key:  namespace.New("quorum").Key("contexts"),
+			cmds: cmds,
+		},
+		n: n,
+	}
+}
+
+// QuorumError is set on the Context by a MakeQuorum Command when fewer than the required number of Commands succeed.
+type QuorumError struct {
+	// Required is the minimum number of Commands that needed to succeed.
+	Required int
+
+	// Total is the number of Commands executed.
+	Total int
+
+	// Errors contains the error of each failed Command, in the order the Commands were provided.
+	Errors []error
+}
+
+func (e *QuorumError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+
+	return fmt.Sprintf("quorum not met: %d of %d succeeded, %d required: %s",
+		e.Total-len(e.Errors), e.Total, e.Required, strings.Join(msgs, "; "))
+}
+
+type quorum struct {
+	*parallel
+	n int
+}
+
+func (q *quorum) String() string {
+	return fmt.Sprintf("Quorum of %d/%d Parallel Commands", q.n, len(q.cmds))
+}
+
+func (q *quorum) Promote(merge MergeFunc, keys ...interface{}) ParallelCommand {
+	q.parallel.Promote(merge, keys...)
+	return q
+}
+
+func (q *quorum) Run(ctx Context, p Printer) {
+	sctx := q.makeSubContexts(ctx)
+	ctx.Set(q.key, sctx)
+
+	wg := sync.WaitGroup{}
+	wg.Add(len(q.cmds))
+
+	for i := range sctx {
+		go q.runParallelCommand(q.cmds[i], sctx[i], p, &wg)
+	}
+
+	wg.Wait()
+	detachSubContexts(sctx)
+
+	err := q.check(sctx, p)
+	if err == nil {
+		if err = q.promote(ctx, sctx); err != nil {
+			p.Err("%v", err)
+		}
+	}
+
+	if err != nil {
+		q.Rollback(ctx, p)
+		ctx.SetErr(err)
+	}
+}
+
+func (q *quorum) DryRun(ctx Context, p Printer) {
+	sctx := q.makeSubContexts(ctx)
+	ctx.Set(q.key, sctx)
+
+	wg := sync.WaitGroup{}
+	wg.Add(len(q.cmds))
+
+	for i := range sctx {
+		go q.dryRunParallelCommand(q.cmds[i], sctx[i], p, &wg)
+	}
+
+	wg.Wait()
+	detachSubContexts(sctx)
+
+	err := q.check(sctx, p)
+	if err == nil {
+		err = q.promote(ctx, sctx)
+	}
+
+	if err != nil {
+		ctx.SetErr(err)
+	}
+}
+
+func (q *quorum) check(sctx []Context, p Printer) error {
+	var errs []error
+	for i := range sctx {
+		if err := sctx[i].Err(); err != nil {
+			p.Warn("%v failed: %v", q.cmds[i], err)
+			errs = append(errs, err)
+		}
+	}
+
+	if len(sctx)-len(errs) >= q.n {
+		return nil
+	}
+
+	return &QuorumError{
+		Required: q.n,
+		Total:    len(sctx),
+		Errors:   errs,
+	}
+}
@@ -0,0 +1,145 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuorum_Interfaces(t *testing.T) {
+	t.Parallel()
+
+	var (
+		q *quorum
+		_ Command      = q
+		_ Rollbacker   = q
+		_ DryRunner    = q
+		_ fmt.Stringer = q
+	)
+}
+
+func TestQuorum_String(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	q := MakeQuorum(1, &MockCommand{}, &MockCommand{})
+	is.Contains(fmt.Sprint(q), "1")
+	is.Contains(fmt.Sprint(q), "2")
+}
+
+func TestQuorum_Run_Met(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+
+	cmdA := &MockCommand{name: "A"}
+	cmdB := &MockCommand{name: "B", err: errors.New("foo")}
+	cmdC := &MockCommand{name: "C"}
+
+	ctx := NewContext()
+	MakeQuorum(2, cmdA, cmdB, cmdC).Run(ctx, DefaultPrinter)
+
+	is.NoError(ctx.Err())
+	for _, cmd := range []*MockCommand{cmdA, cmdB, cmdC} {
+		is.True(cmd.ran)
+		is.False(cmd.rolledBack)
+	}
+}
+
+func TestQuorum_Run_Missed(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	errB := errors.New("foo")
+	errC := errors.New("bar")
+
+	cmdA := &MockCommand{name: "A"}
+	cmdB := &MockCommand{name: "B", err: errB}
+	cmdC := &MockCommand{name: "C", err: errC}
+
+	ctx := NewContext()
+	MakeQuorum(2, cmdA, cmdB, cmdC).Run(ctx, DefaultPrinter)
+
+	err, ok := ctx.Err().(*QuorumError)
+	is.True(ok, "error should be a QuorumError")
+	is.Equal(2, err.Required)
+	is.Equal(3, err.Total)
+	is.Equal([]error{errB, errC}, err.Errors)
+	is.Contains(err.Error(), "1 of 3")
+	is.Contains(err.Error(), "foo")
+	is.Contains(err.Error(), "bar")
+
+	is.True(cmdA.rolledBack, "successful commands should be rolled back")
+	is.False(cmdB.rolledBack)
+	is.False(cmdC.rolledBack)
+}
+
+func TestQuorum_InFailingSequence(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	err := errors.New("foo")
+
+	cmdA := &MockCommand{name: "A"}
+	cmdB := &MockCommand{name: "B", err: errors.New("bar")}
+	cmdC := &MockCommand{name: "C", err: err}
+
+	ctx := NewContext()
+	NewSequence(MakeQuorum(1, cmdA, cmdB), cmdC).Run(ctx, DefaultPrinter)
+
+	is.Equal(err, ctx.Err())
+	is.True(cmdA.rolledBack)
+	is.False(cmdB.rolledBack)
+}
+
+func TestQuorum_DryRun(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+
+	cmdA := &MockCommand{name: "A"}
+	cmdB := &MockCommand{name: "B", err: errors.New("foo")}
+
+	ctx := NewContext()
+	MakeQuorum(1, cmdA, cmdB).(DryRunner).DryRun(ctx, DefaultPrinter)
+	is.NoError(ctx.Err())
+	is.True(cmdA.dryRan)
+	is.False(cmdA.ran)
+
+	ctx = NewContext()
+	MakeQuorum(2, cmdA, cmdB).(DryRunner).DryRun(ctx, DefaultPrinter)
+	is.IsType(&QuorumError{}, ctx.Err())
+	is.False(cmdA.rolledBack)
+}
+
+func TestQuorum_Promote(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+
+	q := MakeQuorum(1,
+		&MockCommand{name: "A", set: "foo"},
+		&MockCommand{name: "B", set: "foo", err: errors.New("bar")},
+	)
+	is.Equal(q, q.Promote(MergeCollect, "foo"))
+
+	ctx := NewContext()
+	q.Run(ctx, DefaultPrinter)
+
+	is.NoError(ctx.Err())
+	val, _ := ctx.Get("foo")
+	is.Equal([]interface{}{"A", nil}, val, "failed commands should not be promoted")
+}
+
+func TestQuorum_InvalidN(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	is.Panics(func() { MakeQuorum(0, &MockCommand{}) }, "quorum of zero should panic")
+	is.Panics(func() { MakeQuorum(-1, &MockCommand{}) }, "negative quorum should panic")
+	is.Panics(func() { MakeQuorum(3, &MockCommand{}, &MockCommand{}) }, "unreachable quorum should panic")
+	is.Panics(func() { MakeQuorum(1) }, "quorum without commands should panic")
+	is.NotPanics(func() { MakeQuorum(2, &MockCommand{}, &MockCommand{}) })
+}
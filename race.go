@@ -0,0 +1,159 @@
+package runner
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/rodaine/runner/internal/namespace"
+)
+
+// Race returns a Command that executes the passed in cmds in parallel, keeping only the first Command to complete
+// successfully. Like MakeParallel, each Command receives its own forked Context. Once a winner is determined, the
+// Contexts of all other Commands are canceled (see Context.Done) and the key-value pairs set by the winner are copied
+// into the parent Context, making them visible to downstream Commands.
+//
+// Any other Command that still completes successfully is rolled back once all Commands have returned. If every Command
+// fails, the error from the first Command (in the order provided) is set on the parent Context.
+//
+// A rollback initiated from a downstream Command only rolls back the winner. A Race without Commands does nothing. During a dry run, the first successful
+// DryRunner wins, but the remaining Commands are not rolled back.
+//
+// This command implements the Rollbacker and DryRunner interfaces.
+func Race(cmds ...Command) Command {
+	ns := namespace.New("race")
+	return &race{
+		ctxKey:    ns.Key("contexts"),
+		winnerKey: ns.Key("winner"),
+		cmds:      cmds,
+	}
+}
+
+// race stores the sub-contexts of its Commands and the index of the winner on the parent Context, so that the state
+// can be serialized with MarshalContext.
+type race struct {
+	ctxKey, winnerKey interface{}
+	cmds              []Command
+}
+
+func (r *race) String() string {
+	return fmt.Sprintf("%d Racing Commands", len(r.cmds))
+}
+
+func (r *race) Run(ctx Context, p Printer) {
+	r.race(ctx, p, PhaseRun, func(cmd Command, sctx Context, p Printer) {
+		cmd.Run(sctx, p)
+	})
+}
+
+func (r *race) Rollback(ctx Context, p Printer) {
+	// a race without Commands has no winner to rollback
+	if len(r.cmds) == 0 {
+		return
+	}
+
+	val, _ := ctx.Get(r.ctxKey)
+	sctx, ok := val.([]Context)
+	val, _ = ctx.Get(r.winnerKey)
+	winner, found := val.(int)
+	if !ok || !found || winner < 0 || winner >= len(sctx) || winner >= len(r.cmds) {
+		panic("context for race winner missing")
+	}
+
+	if rb, ok := r.cmds[winner].(Rollbacker); ok {
+		rollback(sctx[winner], rb, p)
+	}
+}
+
+func (r *race) DryRun(ctx Context, p Printer) {
+	r.race(ctx, p, PhaseDryRun, func(cmd Command, sctx Context, p Printer) {
+		if dr, ok := cmd.(DryRunner); ok {
+			dr.DryRun(sctx, p)
+		}
+	})
+}
+
+func (r *race) race(ctx Context, p Printer, phase Phase, exec func(Command, Context, Printer)) {
+	if len(r.cmds) == 0 {
+		return
+	}
+
+	sctx := make([]Context, len(r.cmds))
+	for i := range sctx {
+		sctx[i] = newSubContext(ctx)
+	}
+
+	done := make(chan int, len(r.cmds))
+	for i := range sctx {
+		go func(i int) {
+			bp, flush := branchPrinter(p, r.cmds[i])
+			cp := begin(sctx[i], r.cmds[i], phase, bp)
+			exec(r.cmds[i], sctx[i], cp)
+			end(sctx[i], r.cmds[i], phase)
+			flush()
+			done <- i
+		}(i)
+	}
+
+	winner := -1
+	for range r.cmds {
+		i := <-done
+		if winner >= 0 || sctx[i].Err() != nil {
+			continue
+		}
+
+		winner = i
+		for j := range sctx {
+			if j != winner {
+				sctx[j].cancel()
+			}
+		}
+	}
+
+	detachSubContexts(sctx)
+
+	if winner < 0 {
+		for i := range sctx {
+			if err := sctx[i].Err(); err != nil {
+				ctx.SetErr(err)
+				return
+			}
+		}
+	}
+
+	p.Debug("race won by %v", r.cmds[winner])
+
+	if phase == PhaseRun {
+		r.rollbackLosers(sctx, winner, p)
+	}
+
+	ctx.Set(r.ctxKey, sctx)
+	ctx.Set(r.winnerKey, winner)
+	for k, v := range sctx[winner].locals() {
+		ctx.Set(k, v)
+	}
+}
+
+func (r *race) rollbackLosers(sctx []Context, winner int, p Printer) {
+	wg := sync.WaitGroup{}
+
+	for i := range sctx {
+		if i == winner || sctx[i].Err() != nil {
+			continue
+		}
+
+		rb, ok := r.cmds[i].(Rollbacker)
+		if !ok {
+			continue
+		}
+
+		wg.Add(1)
+		go func(rb Rollbacker, ctx Context) {
+			bp, flush := branchPrinter(p, rb)
+			rollback(ctx, rb, bp)
+			flush()
+			wg.Done()
+		}(rb, sctx[i])
+	}
+
+	wg.Wait()
+}
@@ -0,0 +1,190 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRace_Interfaces(t *testing.T) {
+	t.Parallel()
+
+	var (
+		r *race
+		_ Command      = r
+		_ Rollbacker   = r
+		_ DryRunner    = r
+		_ fmt.Stringer = r
+	)
+}
+
+func TestRace_String(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	r := Race()
+	is.Contains(fmt.Sprint(r), "0")
+
+	r = Race(&MockCommand{}, &MockCommand{})
+	is.Contains(fmt.Sprint(r), "2")
+}
+
+func TestRace_Run_Empty(t *testing.T) {
+	t.Parallel()
+
+	ctx := NewContext()
+	Race().Run(ctx, DefaultPrinter)
+	assert.NoError(t, ctx.Err())
+}
+
+func TestRace_Run_FirstWins(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+
+	cmdA := &MockCommand{name: "A", set: "A", delay: time.Second}
+	cmdB := &MockCommand{name: "B", set: "B"}
+	cmdC := &MockCommand{name: "C", set: "C", delay: 50 * time.Millisecond, ignoreCancel: true}
+
+	ctx := NewContext()
+	Race(cmdA, cmdB, cmdC).Run(ctx, DefaultPrinter)
+	is.NoError(ctx.Err())
+
+	is.True(cmdA.canceled, "slow command should be canceled")
+	is.False(cmdA.rolledBack, "canceled command should not be rolled back")
+
+	is.True(cmdB.ran)
+	is.False(cmdB.rolledBack, "winner should not be rolled back")
+
+	is.True(cmdC.setVal)
+	is.True(cmdC.rolledBack, "completed loser should be rolled back")
+
+	_, found := ctx.Get("B")
+	is.True(found, "winner's values should be visible on the parent")
+
+	_, found = ctx.Get("C")
+	is.False(found, "loser's values should not be visible on the parent")
+}
+
+func TestRace_Run_Failure(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	errA := errors.New("foo")
+	errB := errors.New("bar")
+
+	cmdA := &MockCommand{name: "A", err: errA, delay: 10 * time.Millisecond}
+	cmdB := &MockCommand{name: "B", err: errB}
+
+	ctx := NewContext()
+	Race(cmdA, cmdB).Run(ctx, DefaultPrinter)
+
+	is.Equal(errA, ctx.Err())
+	is.False(cmdA.canceled)
+	is.False(cmdA.rolledBack)
+	is.False(cmdB.rolledBack)
+}
+
+func TestRace_Run_FailureThenSuccess(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+
+	cmdA := &MockCommand{name: "A", err: errors.New("foo")}
+	cmdB := &MockCommand{name: "B", set: "B", delay: 10 * time.Millisecond}
+
+	ctx := NewContext()
+	Race(cmdA, cmdB).Run(ctx, DefaultPrinter)
+
+	is.NoError(ctx.Err())
+	is.False(cmdB.canceled)
+
+	_, found := ctx.Get("B")
+	is.True(found)
+}
+
+func TestRace_Rollback_Panic(t *testing.T) {
+	t.Parallel()
+
+	assert.Panics(t, func() {
+		Race(&MockCommand{name: "A"}).(Rollbacker).Rollback(NewContext(), DefaultPrinter)
+	})
+}
+
+func TestRace_Rollback_Empty(t *testing.T) {
+	t.Parallel()
+
+	err := errors.New("foo")
+	assert.NotPanics(t, func() {
+		assert.Equal(t, err, Run(Race(), &MockCommand{name: "A", err: err}))
+	}, "a race without Commands has nothing to rollback")
+}
+
+func TestRace_Rollback_Winner(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+
+	cmdA := &MockCommand{name: "A", delay: time.Second}
+	cmdB := &MockCommand{name: "B", set: "B", see: "B"}
+
+	ctx := NewContext()
+	r := Race(cmdA, cmdB).(*race)
+	r.Run(ctx, DefaultPrinter)
+	r.Rollback(ctx, DefaultPrinter)
+
+	is.False(cmdA.rolledBack)
+	is.True(cmdB.rolledBack)
+	is.True(cmdB.seenVal)
+}
+
+func TestRace_InFailingSequence(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	err := errors.New("foo")
+
+	cmdA := &MockCommand{name: "A", delay: time.Second}
+	cmdB := &MockCommand{name: "B"}
+	cmdC := &MockCommand{name: "C", err: err}
+
+	ctx := NewContext()
+	NewSequence(Race(cmdA, cmdB), cmdC).Run(ctx, DefaultPrinter)
+
+	is.Equal(err, ctx.Err())
+	is.False(cmdA.rolledBack)
+	is.True(cmdB.rolledBack)
+}
+
+func TestRace_DryRun(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+
+	cmdA := &MockCommand{name: "A", set: "A", delay: 10 * time.Millisecond, ignoreCancel: true}
+	cmdB := &MockCommand{name: "B", set: "B"}
+
+	ctx := NewContext()
+	Race(cmdA, cmdB).(DryRunner).DryRun(ctx, DefaultPrinter)
+
+	is.NoError(ctx.Err())
+	is.True(cmdA.dryRan)
+	is.True(cmdB.dryRan)
+	is.False(cmdA.ran)
+	is.False(cmdA.rolledBack)
+
+	_, found := ctx.Get("B")
+	is.True(found)
+}
+
+func TestRace_DryRun_Fail(t *testing.T) {
+	t.Parallel()
+
+	err := errors.New("foo")
+	ctx := NewContext()
+	Race(&MockCommand{name: "A", err: err}).(DryRunner).DryRun(ctx, DefaultPrinter)
+	assert.Equal(t, err, ctx.Err())
+}
@@ -0,0 +1,129 @@
+package runner
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// A RateLimiter is a token bucket shared by RateLimited Commands. The bucket holds up to burst tokens and is refilled
+// with one token every interval. A single RateLimiter may be used by any number of Commands, including those running
+// in parallel.
+type RateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	burst    float64
+	tokens   float64
+	last     time.Time
+}
+
+// NewRateLimiter returns a RateLimiter that permits one Command every interval, with bursts of up to burst Commands.
+// The bucket starts full. A burst less than one is treated as one.
+func NewRateLimiter(interval time.Duration, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &RateLimiter{
+		interval: interval,
+		burst:    float64(burst),
+		tokens:   float64(burst),
+		last:     time.Now(),
+	}
+}
+
+// reserve takes a token from the bucket, returning how long the caller must wait before the token is available.
+func (l *RateLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if l.interval > 0 {
+		l.tokens += float64(now.Sub(l.last)) / float64(l.interval)
+	} else {
+		l.tokens = l.burst
+	}
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens * float64(l.interval))
+}
+
+// unreserve returns a token that was reserved but never used.
+func (l *RateLimiter) unreserve() {
+	l.mu.Lock()
+	l.tokens++
+	l.mu.Unlock()
+}
+
+// RateLimited returns a Command that waits for a token from limiter before executing cmd. Waits are logged at the
+// Debug LogLevel. If the Context is canceled while waiting, cmd is not executed and ErrCanceled is set on the Context.
+//
+// Rollbacks are also rate limited, but will wait for a token even if the Context has been canceled.
+//
+// This command implements the Rollbacker and DryRunner interfaces.
+func RateLimited(limiter *RateLimiter, cmd Command) Command {
+	return &rateLimited{
+		limiter: limiter,
+		cmd:     cmd,
+	}
+}
+
+type rateLimited struct {
+	limiter *RateLimiter
+	cmd     Command
+}
+
+func (r *rateLimited) String() string {
+	return fmt.Sprintf("%s [rate limited]", r.cmd)
+}
+
+func (r *rateLimited) Run(ctx Context, p Printer) {
+	if r.wait(ctx, p) {
+		r.cmd.Run(ctx, p)
+	}
+}
+
+func (r *rateLimited) Rollback(ctx Context, p Printer) {
+	if cmd, ok := r.cmd.(Rollbacker); ok {
+		if wait := r.limiter.reserve(); wait > 0 {
+			p.Debug("rate limited: waiting %v", wait)
+			time.Sleep(wait)
+		}
+		cmd.Rollback(ctx, p)
+	}
+}
+
+func (r *rateLimited) DryRun(ctx Context, p Printer) {
+	if cmd, ok := r.cmd.(DryRunner); ok && r.wait(ctx, p) {
+		cmd.DryRun(ctx, p)
+	}
+}
+
+func (r *rateLimited) wait(ctx Context, p Printer) (ok bool) {
+	wait := r.limiter.reserve()
+	if wait <= 0 {
+		return true
+	}
+
+	p.Debug("rate limited: waiting %v", wait)
+
+	t := time.NewTimer(wait)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return true
+	case <-ctx.Done():
+		r.limiter.unreserve()
+		p.Debug("rate limited: canceled while waiting")
+		ctx.SetErr(ErrCanceled)
+		return false
+	}
+}
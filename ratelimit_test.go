@@ -0,0 +1,115 @@
+package runner
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimited_Interfaces(t *testing.T) {
+	t.Parallel()
+
+	var (
+		r *rateLimited
+		_ Command      = r
+		_ Rollbacker   = r
+		_ DryRunner    = r
+		_ fmt.Stringer = r
+	)
+}
+
+func TestRateLimited_String(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	cmd := &MockCommand{name: "foo"}
+
+	str := fmt.Sprint(RateLimited(NewRateLimiter(time.Second, 1), cmd))
+	is.Contains(str, fmt.Sprint(cmd))
+	is.Contains(str, "rate limited")
+}
+
+func TestRateLimiter_reserve(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	l := NewRateLimiter(time.Hour, 2)
+
+	is.Zero(l.reserve())
+	is.Zero(l.reserve())
+	is.InDelta(float64(time.Hour), float64(l.reserve()), float64(time.Second))
+	is.InDelta(float64(2*time.Hour), float64(l.reserve()), float64(time.Second))
+
+	l.unreserve()
+	is.InDelta(float64(2*time.Hour), float64(l.reserve()), float64(time.Second))
+
+	l = NewRateLimiter(0, 0)
+	is.Zero(l.reserve())
+	is.Zero(l.reserve())
+}
+
+func TestRateLimited_Run_Shared(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	buf := &syncBuffer{}
+	l := NewRateLimiter(20*time.Millisecond, 1)
+
+	cmds := make([]Command, 4)
+	for i := range cmds {
+		cmds[i] = RateLimited(l, &MockCommand{name: fmt.Sprint(i)})
+	}
+
+	start := time.Now()
+	is.NoError(RunWithPrinter(NewPrinter(buf, LevelDebug), MakeParallel(cmds...)))
+	is.True(time.Since(start) >= 50*time.Millisecond, "commands should be throttled")
+	is.Contains(buf.String(), "rate limited: waiting")
+}
+
+func TestRateLimited_Run_Canceled(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	l := NewRateLimiter(time.Hour, 1)
+	l.reserve()
+
+	cmd := &MockCommand{name: "A"}
+	ctx := NewContext()
+	ctx.cancel()
+
+	RateLimited(l, cmd).Run(ctx, DefaultPrinter)
+	is.Equal(ErrCanceled, ctx.Err())
+	is.False(cmd.ran)
+	is.InDelta(float64(time.Hour), float64(l.reserve()), float64(time.Second), "canceled wait should return its token")
+}
+
+func TestRateLimited_Rollback(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	l := NewRateLimiter(10*time.Millisecond, 1)
+	l.reserve()
+
+	cmd := &MockCommand{name: "A"}
+	ctx := NewContext()
+	ctx.cancel()
+
+	RateLimited(l, cmd).(Rollbacker).Rollback(ctx, DefaultPrinter)
+	is.True(cmd.rolledBack, "rollbacks should not be canceled")
+}
+
+func TestRateLimited_DryRun(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	l := NewRateLimiter(time.Hour, 1)
+	cmd := &MockCommand{name: "A"}
+
+	ctx := NewContext()
+	RateLimited(l, cmd).(DryRunner).DryRun(ctx, DefaultPrinter)
+	is.NoError(ctx.Err())
+	is.True(cmd.dryRan)
+	is.False(cmd.ran)
+}
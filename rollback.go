@@ -0,0 +1,75 @@
+package runner
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// rollback rolls back cmd with a read-only view of ctx, notifying any Observers.
+func rollback(ctx Context, cmd Rollbacker, p Printer) {
+	cp := begin(ctx, cmd, PhaseRollback, p)
+	cmd.Rollback(newRollbackContext(ctx), cp)
+	end(ctx, cmd, PhaseRollback)
+}
+
+// rollbackCtx is a read-only view over a Context passed to a Rollbacker. Values set on it are written to a scratch
+// layer that is only visible through the view, and errors set on it are discarded, so a rollback cannot alter the
+// state seen by the rollbacks of other Commands.
+type rollbackCtx struct {
+	Context
+
+	mu   sync.RWMutex
+	kvs  hash
+	prov provs
+}
+
+func newRollbackContext(parent Context) Context {
+	return &rollbackCtx{
+		Context: parent,
+		kvs:     make(hash),
+		prov:    make(provs),
+	}
+}
+
+func (rc *rollbackCtx) SetErr(err error) {}
+
+func (rc *rollbackCtx) Get(key interface{}) (val interface{}, found bool) {
+	rc.mu.RLock()
+	val, found = rc.kvs[key]
+	rc.mu.RUnlock()
+
+	if !found {
+		val, found = rc.Context.Get(key)
+	}
+	return
+}
+
+func (rc *rollbackCtx) Set(key, val interface{}) {
+	prov := Provenance{
+		Command: strings.Join(rc.path(), commandPathSep),
+		Time:    time.Now(),
+	}
+
+	rc.mu.Lock()
+	rc.kvs[key] = val
+	rc.prov[key] = prov
+	rc.mu.Unlock()
+}
+
+func (rc *rollbackCtx) Provenance(key interface{}) (prov Provenance, found bool) {
+	rc.mu.RLock()
+	prov, found = rc.prov[key]
+	rc.mu.RUnlock()
+
+	if !found {
+		prov, found = rc.Context.Provenance(key)
+	}
+	return
+}
+
+func (rc *rollbackCtx) unsetErr() {}
+
+func (rc *rollbackCtx) self() Context {
+	return rc.Context.self()
+}
@@ -0,0 +1,86 @@
+package runner
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type writingRollbacker struct {
+	name string
+	seen interface{}
+	err  error
+}
+
+func (c *writingRollbacker) Run(ctx Context, p Printer) {
+	ctx.Set("key", c.name)
+}
+
+func (c *writingRollbacker) Rollback(ctx Context, p Printer) {
+	c.seen, _ = ctx.Get("key")
+	ctx.Set("key", c.name+" rolled back")
+	ctx.SetErr(errors.New("rollback " + c.name))
+
+	val, _ := ctx.Get("key")
+	if val != c.name+" rolled back" {
+		c.err = errors.New("rollback should see its own writes")
+	}
+}
+
+func TestRollbackContext(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+
+	ctx := NewContext()
+	ctx.Set("foo", "bar")
+
+	rc := newRollbackContext(ctx)
+	rc.Set("foo", "baz")
+	rc.SetErr(errors.New("foo"))
+
+	val, _ := rc.Get("foo")
+	is.Equal("baz", val, "view should see its own writes")
+	_, found := rc.Provenance("foo")
+	is.True(found)
+
+	val, _ = ctx.Get("foo")
+	is.Equal("bar", val, "writes should not reach the underlying context")
+	is.NoError(ctx.Err())
+	is.NoError(rc.Err())
+	is.Equal(ctx, rc.self())
+}
+
+func TestRollback_Sequence(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	err := errors.New("foo")
+
+	cmdA := &writingRollbacker{name: "A"}
+	cmdB := &writingRollbacker{name: "B"}
+
+	is.Equal(err, Run(cmdA, cmdB, &MockCommand{name: "C", err: err}))
+	is.NoError(cmdA.err)
+	is.NoError(cmdB.err)
+	is.Equal("A", cmdA.seen, "rollback of B should not clobber values seen by A")
+	is.Equal("B", cmdB.seen)
+}
+
+func TestRollback_Parallel(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	err := errors.New("foo")
+
+	cmdA := &writingRollbacker{name: "A"}
+	seq := NewSequence(cmdA, &writingRollbacker{name: "B"})
+
+	ctx := NewContext()
+	MakeParallel(seq, &MockCommand{name: "C", err: err}).Run(ctx, DefaultPrinter)
+
+	is.Equal(err, ctx.Err())
+	is.NoError(cmdA.err)
+	is.Equal("A", cmdA.seen)
+}
@@ -9,9 +9,17 @@ func Run(cmds ...Command) error {
 // RunWithPrinter executes the passed in Commands in sequence, returning an error if the execution failed and a rollback
 // occurred. The provided Printer is passed to all commands for logging.
 func RunWithPrinter(p Printer, cmds ...Command) error {
-	ctx := NewContext()
-	(&sequence{cmds: cmds}).Run(ctx, p)
-	return ctx.Err()
+	return RunWithContext(NewContext(), p, cmds...)
+}
+
+// RunWithContext executes the passed in Commands in sequence with the provided Context, returning an error if the
+// execution failed and a rollback occurred. The provided Printer is passed to all commands for logging.
+//
+// Values set on the Context before calling RunWithContext are available to the Commands as inputs. If the run
+// succeeds, values set by the Commands remain readable from the Context afterwards; if it fails, they should not be
+// relied upon. A Context should only be used for a single run.
+func RunWithContext(ctx Context, p Printer, cmds ...Command) error {
+	return Execute(cmds, WithPrinter(p), WithContext(ctx))
 }
 
 // DryRun simulates a Run of the passed in Commands, without write/destructive actions. The DefaultPrinter is passed to
@@ -24,6 +32,11 @@ func DryRun(cmds ...Command) {
 // is passed to all commands for logging.
 func DryRunWithPrinter(p Printer, cmds ...Command) {
 	// TODO: estimate depth
-	ctx := NewContext()
-	(&sequence{cmds: cmds}).DryRun(ctx, p)
+	DryRunWithContext(NewContext(), p, cmds...)
+}
+
+// DryRunWithContext simulates a RunWithContext of the passed in Commands, without write/destructive actions. Values
+// set by the DryRunner Commands remain readable from the Context afterwards.
+func DryRunWithContext(ctx Context, p Printer, cmds ...Command) {
+	_ = Execute(cmds, WithPrinter(p), WithContext(ctx), WithDryRun())
 }
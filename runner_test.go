@@ -0,0 +1,60 @@
+package runner
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunWithContext(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+
+	ctx := NewContext()
+	ctx.Set("in", "foo")
+
+	cmd := &MockCommand{name: "A", see: "in", set: "out"}
+	is.NoError(RunWithContext(ctx, DefaultPrinter, cmd))
+	is.True(cmd.seenVal, "commands should see seeded values")
+
+	val, found := ctx.Get("out")
+	is.True(found, "outputs should be readable after the run")
+	is.Equal("A", val)
+}
+
+func TestRunWithContext_Failure(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	err := errors.New("foo")
+
+	ctx := NewContext()
+	ctx.Set("in", "foo")
+
+	is.Equal(err, RunWithContext(ctx, DefaultPrinter,
+		&MockCommand{name: "A", set: "out"},
+		&MockCommand{name: "B", err: err},
+	))
+
+	_, found := ctx.Get("in")
+	is.True(found, "seeded values should survive the rollback")
+}
+
+func TestDryRunWithContext(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+
+	ctx := NewContext()
+	ctx.Set("in", "foo")
+
+	cmd := &MockCommand{name: "A", see: "in", set: "out"}
+	DryRunWithContext(ctx, DefaultPrinter, cmd)
+
+	is.True(cmd.dryRan)
+	is.True(cmd.seenVal)
+	_, found := ctx.Get("out")
+	is.True(found)
+}
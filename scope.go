@@ -0,0 +1,85 @@
+package runner
+
+import "fmt"
+
+// Scope returns a Command that executes cmd with its Context keys prefixed by name. Values set within the scope are
+// only visible to Commands in the same scope, preventing collisions between independent sub-pipelines that use the
+// same keys. Commands within the scope can still read values set outside of it, though values set within the scope
+// shadow them.
+//
+// This command implements the Rollbacker and DryRunner interfaces.
+func Scope(name string, cmd Command) Command {
+	return &scope{
+		name: name,
+		cmd:  cmd,
+	}
+}
+
+type scope struct {
+	name string
+	cmd  Command
+}
+
+func (s *scope) String() string {
+	return fmt.Sprintf("%s [scope %s]", s.cmd, s.name)
+}
+
+func (s *scope) Run(ctx Context, p Printer) {
+	s.cmd.Run(newScopedContext(ctx, s.name), p)
+}
+
+func (s *scope) Rollback(ctx Context, p Printer) {
+	if cmd, ok := s.cmd.(Rollbacker); ok {
+		cmd.Rollback(newScopedContext(ctx, s.name), p)
+	}
+}
+
+func (s *scope) DryRun(ctx Context, p Printer) {
+	if cmd, ok := s.cmd.(DryRunner); ok {
+		cmd.DryRun(newScopedContext(ctx, s.name), p)
+	}
+}
+
+type scopedKey struct {
+	scope string
+	key   interface{}
+}
+
+func (k scopedKey) String() string {
+	return fmt.Sprintf("%s/%v", k.scope, k.key)
+}
+
+// scopedCtx is a view over a Context that prefixes all keys it sets with a scope.
+type scopedCtx struct {
+	Context
+	scope string
+}
+
+func newScopedContext(parent Context, scope string) Context {
+	return &scopedCtx{
+		Context: parent,
+		scope:   scope,
+	}
+}
+
+func (sc *scopedCtx) Get(key interface{}) (val interface{}, found bool) {
+	if val, found = sc.Context.Get(scopedKey{sc.scope, key}); !found {
+		val, found = sc.Context.Get(key)
+	}
+	return
+}
+
+func (sc *scopedCtx) Set(key, val interface{}) {
+	sc.Context.Set(scopedKey{sc.scope, key}, val)
+}
+
+func (sc *scopedCtx) Provenance(key interface{}) (prov Provenance, found bool) {
+	if prov, found = sc.Context.Provenance(scopedKey{sc.scope, key}); !found {
+		prov, found = sc.Context.Provenance(key)
+	}
+	return
+}
+
+func (sc *scopedCtx) self() Context {
+	return sc.Context.self()
+}
@@ -0,0 +1,114 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScope_Interfaces(t *testing.T) {
+	t.Parallel()
+
+	var (
+		s *scope
+		_ Command      = s
+		_ Rollbacker   = s
+		_ DryRunner    = s
+		_ fmt.Stringer = s
+	)
+}
+
+func TestScope_String(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	cmd := &MockCommand{name: "foo"}
+
+	str := fmt.Sprint(Scope("bar", cmd))
+	is.Contains(str, fmt.Sprint(cmd))
+	is.Contains(str, "scope bar")
+}
+
+func TestScope_Run(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+
+	ctx := NewContext()
+	ctx.Set("in", "outer")
+
+	seen := &MockCommand{name: "B", see: "in"}
+	NewSequence(
+		Scope("a", &MockCommand{name: "A", set: "out"}),
+		Scope("b", NewSequence(&MockCommand{name: "B", set: "out"}, seen)),
+	).Run(ctx, DefaultPrinter)
+	is.NoError(ctx.Err())
+
+	is.True(seen.seenVal, "scoped commands should see values set outside the scope")
+
+	_, found := ctx.Get("out")
+	is.False(found, "values set in a scope should not be visible outside it")
+
+	val, _ := ctx.Get(scopedKey{"a", "out"})
+	is.Equal("A", val)
+	val, _ = ctx.Get(scopedKey{"b", "out"})
+	is.Equal("B", val)
+
+	prov, found := ctx.Provenance(scopedKey{"a", "out"})
+	is.True(found)
+	is.Contains(prov.Command, "MOCK A")
+
+	sctx := newScopedContext(ctx, "a")
+	prov, _ = sctx.Provenance("out")
+	is.Contains(prov.Command, "MOCK A")
+	prov, found = sctx.Provenance("in")
+	is.True(found, "scoped provenance should fall back to unscoped keys")
+}
+
+func TestScope_Rollback(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	err := errors.New("foo")
+
+	cmdA := &MockCommand{name: "A", set: "out", see: "out"}
+	ctx := NewContext()
+	NewSequence(Scope("a", cmdA), &MockCommand{name: "B", err: err}).Run(ctx, DefaultPrinter)
+
+	is.Equal(err, ctx.Err())
+	is.True(cmdA.rolledBack)
+	is.True(cmdA.seenVal, "rollbacks should see scoped values")
+}
+
+func TestScope_DryRun(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	cmd := &MockCommand{name: "A", set: "out"}
+
+	ctx := NewContext()
+	Scope("a", cmd).(DryRunner).DryRun(ctx, DefaultPrinter)
+
+	is.True(cmd.dryRan)
+	_, found := ctx.Get("out")
+	is.False(found)
+}
+
+func TestScope_Locks(t *testing.T) {
+	t.Parallel()
+
+	done := make(chan error)
+	go func() {
+		done <- Run(WithLock("foo", Scope("a", WithLock("foo", &MockCommand{name: "A"}))))
+	}()
+
+	select {
+	case err := <-done:
+		assert.IsType(t, &DeadlockError{}, err, "scopes should not hide lock holders")
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "deadlock was not detected")
+	}
+}
@@ -6,8 +6,9 @@ import "fmt"
 // passed into Run, RunWithPrinter, DryRun, and DryRunWithPrinter are initially wrapped by this Command.
 //
 // If a Command within the sequence fails, execution stops and a rollback is performed over any previously run Commands
-// in reverse order. Commands that don't satisfy the Rollbacker or DryRunner interfaces are noted and skipped during a
-// rollback or dry run, respectively.
+// in reverse order. Likewise, if the Context is canceled (see Context.Done), no further Commands are run, ErrCanceled
+// is set on the Context, and the previously run Commands are rolled back. Commands that don't satisfy the Rollbacker
+// or DryRunner interfaces are noted and skipped during a rollback or dry run, respectively.
 //
 // This command implements the Rollbacker and DryRunner interfaces.
 func NewSequence(cmds ...Command) Command {
@@ -27,7 +28,9 @@ func (s *sequence) String() string {
 
 func (s *sequence) Run(ctx Context, p Printer) {
 	ctx.push()
-	s.runSubCommands(ctx, p, s.cmds)
+	if !s.canceled(ctx) {
+		s.runSubCommands(ctx, p, s.cmds)
+	}
 }
 
 func (s *sequence) Rollback(ctx Context, p Printer) {
@@ -47,10 +50,26 @@ func (s *sequence) runSubCommands(ctx Context, p Printer, cmds []Command) {
 
 	// run the next Command
 	ctx.push()
-	cmds[0].Run(ctx, p)
+	cp := begin(ctx, cmds[0], PhaseRun, p)
+	cmds[0].Run(ctx, cp)
+	end(ctx, cmds[0], PhaseRun)
+	DumpContext(ctx, p)
+
+	// if there was an error, exit now
+	if ctx.Err() != nil {
+		return
+	}
 
-	// if it was the last or there was an error, exit now
-	if len(cmds) == 1 || ctx.Err() != nil {
+	// if the Context was canceled while running, rollback now
+	if s.canceled(ctx) {
+		if cmd, ok := cmds[0].(Rollbacker); ok {
+			rollback(ctx, cmd, p)
+		}
+		return
+	}
+
+	// if it was the last, exit now
+	if len(cmds) == 1 {
 		return
 	}
 
@@ -61,7 +80,7 @@ func (s *sequence) runSubCommands(ctx Context, p Printer, cmds []Command) {
 	if ctx.Err() != nil {
 		ctx.pop()
 		if cmd, ok := cmds[0].(Rollbacker); ok {
-			cmd.Rollback(ctx, p)
+			rollback(ctx, cmd, p)
 		}
 	}
 }
@@ -72,7 +91,7 @@ func (s *sequence) rollbackSubCommands(ctx Context, p Printer, cmds []Command) {
 	}
 
 	if cmd, ok := cmds[len(cmds)-1].(Rollbacker); ok {
-		cmd.Rollback(ctx, p)
+		rollback(ctx, cmd, p)
 	}
 	ctx.pop()
 
@@ -84,13 +103,16 @@ func (s *sequence) rollbackSubCommands(ctx Context, p Printer, cmds []Command) {
 }
 
 func (s *sequence) dryRunSubCommands(ctx Context, p Printer, cmds []Command) {
-	if len(cmds) == 0 || ctx.Err() != nil {
+	if len(cmds) == 0 || ctx.Err() != nil || s.canceled(ctx) {
 		return
 	}
 
 	ctx.push()
 	if cmd, ok := cmds[0].(DryRunner); ok {
-		cmd.DryRun(ctx, p)
+		cp := begin(ctx, cmd, PhaseDryRun, p)
+		cmd.DryRun(ctx, cp)
+		end(ctx, cmd, PhaseDryRun)
+		DumpContext(ctx, p)
 	}
 
 	if len(cmds) == 1 {
@@ -99,3 +121,14 @@ func (s *sequence) dryRunSubCommands(ctx Context, p Printer, cmds []Command) {
 
 	s.dryRunSubCommands(ctx, p, cmds[1:])
 }
+
+// canceled sets ErrCanceled on the Context if it has been canceled, preventing any further Commands from being run.
+func (s *sequence) canceled(ctx Context) bool {
+	select {
+	case <-ctx.Done():
+		ctx.SetErr(ErrCanceled)
+		return true
+	default:
+		return false
+	}
+}
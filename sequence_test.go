@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -204,3 +205,39 @@ func TestSequence_Rollback_SequenceInSequence(t *testing.T) {
 	is.True(cmdC.ran)
 	is.True(cmdC.failed)
 }
+
+func TestSequence_Run_Canceled(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+
+	cmdA := &MockCommand{name: "A"}
+	cmdB := &MockCommand{name: "B", delay: time.Second, ignoreCancel: true}
+	cmdC := &MockCommand{name: "C"}
+
+	ctx := NewContext()
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		ctx.cancel()
+	}()
+
+	NewSequence(cmdA, cmdB, cmdC).Run(ctx, DefaultPrinter)
+
+	is.Equal(ErrCanceled, ctx.Err())
+	is.True(cmdA.rolledBack)
+	is.True(cmdB.ran)
+	is.True(cmdB.rolledBack, "in-flight command should be rolled back once complete")
+	is.False(cmdC.ran)
+}
+
+func TestSequence_DryRun_Canceled(t *testing.T) {
+	t.Parallel()
+
+	cmd := &MockCommand{name: "A"}
+	ctx := NewContext()
+	ctx.cancel()
+
+	NewSequence(cmd).(DryRunner).DryRun(ctx, DefaultPrinter)
+	assert.Equal(t, ErrCanceled, ctx.Err())
+	assert.False(t, cmd.dryRan)
+}
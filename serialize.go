@@ -0,0 +1,334 @@
+package runner
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rodaine/runner/internal/namespace"
+)
+
+// RegisterCodec registers the functions used to encode and decode values of type T when marshalling a Context to JSON.
+// The name identifies the type in the encoded output and must be unique, as must T; RegisterCodec panics if either has
+// already been registered. Values are matched to codecs by their exact dynamic type, so T should be a concrete type.
+// Codecs for string, bool, int, int64, float64, []byte, and time.Duration are registered by default.
+func RegisterCodec[T any](name string, encode func(T) ([]byte, error), decode func([]byte) (T, error)) {
+	c := &codec{
+		name: name,
+		typ:  reflect.TypeOf((*T)(nil)).Elem(),
+		encode: func(v interface{}) ([]byte, error) {
+			return encode(v.(T))
+		},
+		decode: func(b []byte) (interface{}, error) {
+			return decode(b)
+		},
+	}
+
+	codecs.Lock()
+	defer codecs.Unlock()
+
+	if _, found := codecs.byName[name]; found || isReservedCodec(name) {
+		panic(fmt.Sprintf("codec name %q already registered", name))
+	}
+	if _, found := codecs.byType[c.typ]; found {
+		panic(fmt.Sprintf("codec for type %v already registered", c.typ))
+	}
+
+	codecs.byName[name] = c
+	codecs.byType[c.typ] = c
+}
+
+// RegisterJSONCodec registers a codec for values of type T using the encoding/json package. See RegisterCodec.
+func RegisterJSONCodec[T any](name string) {
+	RegisterCodec(name,
+		func(v T) ([]byte, error) { return json.Marshal(v) },
+		func(b []byte) (v T, err error) { err = json.Unmarshal(b, &v); return },
+	)
+}
+
+// UnserializableError is returned by MarshalContext if any keys or values in the Context have no registered codec.
+type UnserializableError struct {
+	// Entries describes each key-value pair that could not be serialized.
+	Entries []ContextEntry
+}
+
+func (e *UnserializableError) Error() string {
+	msgs := make([]string, len(e.Entries))
+	for i, entry := range e.Entries {
+		msgs[i] = fmt.Sprintf("%v (key %T, value %v)", entry.Key, entry.Key, entry.Type)
+	}
+	return fmt.Sprintf("cannot serialize %d context values: %s", len(msgs), strings.Join(msgs, ", "))
+}
+
+// MarshalContext encodes the Context as JSON, including every layer of key-value pairs, their Provenance, the
+// Context's error, and the sub-contexts of any parallel Commands. Keys and values are encoded using the codecs
+// registered with RegisterCodec. Errors are encoded by their message and nil values are always supported.
+//
+// If any key or value cannot be encoded, an *UnserializableError listing all of them is returned.
+//
+// Commands such as MakeParallel, Race and MakeFailable store their internal state under keys private to each Command
+// instance, identified by a counter local to the process. A decoded Context can therefore only be used with the same
+// Command instances that ran with it, such as to roll them back; identical Commands constructed again, including by a
+// restarted process, do not find their state.
+func MarshalContext(ctx Context) ([]byte, error) {
+	enc := &ctxEncoder{}
+	jc := enc.encode(ctx, ctx.layers(), 0)
+	if len(enc.failed) > 0 {
+		return nil, &UnserializableError{Entries: enc.failed}
+	}
+	return json.Marshal(jc)
+}
+
+// UnmarshalContext decodes a root Context from JSON produced by MarshalContext.
+func UnmarshalContext(data []byte) (Context, error) {
+	var jc jsonContext
+	if err := json.Unmarshal(data, &jc); err != nil {
+		return nil, err
+	}
+
+	root := NewContext()
+	if err := decodeContext(root, root.(*ctx), &jc); err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+const (
+	nilCodec         = "nil"
+	errorCodec       = "error"
+	subContextsCodec = "runner.subcontexts"
+	scopedKeyCodec   = "runner.scoped"
+)
+
+var codecs = struct {
+	sync.RWMutex
+	byName map[string]*codec
+	byType map[reflect.Type]*codec
+}{
+	byName: make(map[string]*codec),
+	byType: make(map[reflect.Type]*codec),
+}
+
+func init() {
+	RegisterJSONCodec[string]("string")
+	RegisterJSONCodec[bool]("bool")
+	RegisterJSONCodec[int]("int")
+	RegisterJSONCodec[int64]("int64")
+	RegisterJSONCodec[float64]("float64")
+	RegisterJSONCodec[[]byte]("bytes")
+	RegisterJSONCodec[time.Duration]("duration")
+
+	RegisterJSONCodec[namespace.Key]("runner.key")
+}
+
+type codec struct {
+	name   string
+	typ    reflect.Type
+	encode func(interface{}) ([]byte, error)
+	decode func([]byte) (interface{}, error)
+}
+
+type jsonContext struct {
+	Err    string        `json:"err,omitempty"`
+	Layers [][]jsonEntry `json:"layers"`
+}
+
+type jsonEntry struct {
+	Key   jsonValue  `json:"key"`
+	Value jsonValue  `json:"value"`
+	SetBy Provenance `json:"setBy"`
+}
+
+type jsonValue struct {
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+type jsonScopedKey struct {
+	Scope string    `json:"scope"`
+	Key   jsonValue `json:"key"`
+}
+
+func isReservedCodec(name string) bool {
+	switch name {
+	case nilCodec, errorCodec, subContextsCodec, scopedKeyCodec:
+		return true
+	}
+	return false
+}
+
+type ctxEncoder struct {
+	failed []ContextEntry
+}
+
+func (e *ctxEncoder) encode(c Context, layers []layer, fork int) *jsonContext {
+	jc := &jsonContext{Layers: make([][]jsonEntry, len(layers))}
+	if err := c.Err(); err != nil {
+		jc.Err = err.Error()
+	}
+
+	for i, l := range layers {
+		jc.Layers[i] = make([]jsonEntry, 0, len(l.kvs))
+		for k, v := range l.kvs {
+			key, kerr := e.value(k, fork)
+			val, verr := e.value(v, fork)
+			if kerr != nil || verr != nil {
+				e.failed = append(e.failed, ContextEntry{
+					Key:   k,
+					Value: v,
+					Type:  reflect.TypeOf(v),
+					Layer: i,
+					Fork:  fork,
+					SetBy: l.prov[k],
+				})
+				continue
+			}
+
+			jc.Layers[i] = append(jc.Layers[i], jsonEntry{Key: key, Value: val, SetBy: l.prov[k]})
+		}
+	}
+
+	return jc
+}
+
+func (e *ctxEncoder) value(v interface{}, fork int) (jv jsonValue, err error) {
+	if v == nil {
+		return jsonValue{Type: nilCodec}, nil
+	}
+
+	codecs.RLock()
+	c, found := codecs.byType[reflect.TypeOf(v)]
+	codecs.RUnlock()
+
+	switch {
+	case found:
+		jv.Type = c.name
+		jv.Value, err = c.encode(v)
+	case isScopedKey(v):
+		sk := v.(scopedKey)
+		var key jsonValue
+		if key, err = e.value(sk.key, fork); err == nil {
+			jv.Type = scopedKeyCodec
+			jv.Value, err = json.Marshal(jsonScopedKey{Scope: sk.scope, Key: key})
+		}
+	case isSubContexts(v):
+		sctx := v.([]Context)
+		subs := make([]*jsonContext, len(sctx))
+		for i, sc := range sctx {
+			subs[i] = e.encode(sc, sc.(*subCtx).ctx.layers(), fork+1)
+		}
+		jv.Type = subContextsCodec
+		jv.Value, err = json.Marshal(subs)
+	case isError(v):
+		jv.Type = errorCodec
+		jv.Value, err = json.Marshal(v.(error).Error())
+	default:
+		err = fmt.Errorf("no codec registered for %T", v)
+	}
+
+	return
+}
+
+func isSubContexts(v interface{}) bool {
+	sctx, ok := v.([]Context)
+	if !ok {
+		return false
+	}
+
+	for _, sc := range sctx {
+		if _, ok = sc.(*subCtx); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func isScopedKey(v interface{}) bool {
+	_, ok := v.(scopedKey)
+	return ok
+}
+
+func isError(v interface{}) bool {
+	_, ok := v.(error)
+	return ok
+}
+
+func decodeContext(c Context, dst *ctx, jc *jsonContext) error {
+	if jc.Err != "" {
+		dst.err = errors.New(jc.Err)
+	}
+
+	for i, entries := range jc.Layers {
+		if i > 0 {
+			dst.push()
+		}
+
+		for _, entry := range entries {
+			k, err := decodeValue(c, entry.Key)
+			if err != nil {
+				return err
+			}
+			if k != nil && !reflect.TypeOf(k).Comparable() {
+				return fmt.Errorf("context key of type %T is not comparable", k)
+			}
+
+			v, err := decodeValue(c, entry.Value)
+			if err != nil {
+				return err
+			}
+
+			dst.store.set(k, v, entry.SetBy)
+		}
+	}
+
+	return nil
+}
+
+func decodeValue(parent Context, jv jsonValue) (interface{}, error) {
+	switch jv.Type {
+	case nilCodec:
+		return nil, nil
+	case errorCodec:
+		var msg string
+		if err := json.Unmarshal(jv.Value, &msg); err != nil {
+			return nil, err
+		}
+		return errors.New(msg), nil
+	case scopedKeyCodec:
+		var jk jsonScopedKey
+		if err := json.Unmarshal(jv.Value, &jk); err != nil {
+			return nil, err
+		}
+		key, err := decodeValue(parent, jk.Key)
+		return scopedKey{scope: jk.Scope, key: key}, err
+	case subContextsCodec:
+		var subs []*jsonContext
+		if err := json.Unmarshal(jv.Value, &subs); err != nil {
+			return nil, err
+		}
+
+		sctx := make([]Context, len(subs))
+		for i, sub := range subs {
+			sc := newSubContext(parent).(*subCtx)
+			if err := decodeContext(sc, sc.ctx.(*ctx), sub); err != nil {
+				return nil, err
+			}
+			sc.detach()
+			sctx[i] = sc
+		}
+		return sctx, nil
+	}
+
+	codecs.RLock()
+	c, found := codecs.byName[jv.Type]
+	codecs.RUnlock()
+
+	if !found {
+		return nil, fmt.Errorf("no codec registered for %q", jv.Type)
+	}
+	return c.decode(jv.Value)
+}
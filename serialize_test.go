@@ -0,0 +1,232 @@
+package runner
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/rodaine/runner/internal/namespace"
+	"github.com/stretchr/testify/assert"
+)
+
+type serializeTestValue struct {
+	N int
+}
+
+func init() {
+	RegisterCodec("runner.serializeTestValue",
+		func(v serializeTestValue) ([]byte, error) { return []byte(strconv.Itoa(v.N)), nil },
+		func(b []byte) (v serializeTestValue, err error) { v.N, err = strconv.Atoi(string(b)); return },
+	)
+}
+
+func TestRegisterCodec_Duplicate(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	is.Panics(func() { RegisterJSONCodec[string]("another string") }, "types should be unique")
+	is.Panics(func() { RegisterJSONCodec[int8]("string") }, "names should be unique")
+	is.Panics(func() { RegisterJSONCodec[int8]("nil") }, "builtin names should be reserved")
+}
+
+func TestMarshalContext_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+
+	ctx := NewContext()
+	ctx.Set("str", "foo")
+	ctx.Set(123, []byte("bar"))
+	ctx.push()
+	ctx.enter("cmd")
+	ctx.Set("str", "baz")
+	ctx.Set("custom", serializeTestValue{N: 7})
+	ctx.Set("err", errors.New("fizz"))
+	ctx.Set("nil", nil)
+	ctx.Set("dur", time.Second)
+	ctx.leave()
+	ctx.SetErr(errors.New("buzz"))
+
+	b, err := MarshalContext(ctx)
+	is.NoError(err)
+	is.True(json.Valid(b))
+
+	out, err := UnmarshalContext(b)
+	is.NoError(err)
+
+	is.EqualError(out.Err(), "buzz")
+
+	val, _ := out.Get("str")
+	is.Equal("baz", val)
+	val, _ = out.Get(123)
+	is.Equal([]byte("bar"), val)
+	val, _ = out.Get("custom")
+	is.Equal(serializeTestValue{N: 7}, val)
+	val, _ = out.Get("err")
+	is.EqualError(val.(error), "fizz")
+	val, found := out.Get("nil")
+	is.True(found)
+	is.Nil(val)
+	val, _ = out.Get("dur")
+	is.Equal(time.Second, val)
+
+	prov, _ := out.Provenance("str")
+	is.Equal("cmd", prov.Command)
+
+	out.pop()
+	val, _ = out.Get("str")
+	is.Equal("foo", val, "layers should be preserved")
+}
+
+func TestMarshalContext_SubContexts(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+
+	ctx := NewContext()
+	ctx.Set("foo", "bar")
+	p := MakeParallel(
+		&MockCommand{name: "A", set: "A"},
+		&MockCommand{name: "B", set: "B"},
+	).(*parallel)
+	p.Run(ctx, DefaultPrinter)
+
+	b, err := MarshalContext(ctx)
+	is.NoError(err)
+
+	out, err := UnmarshalContext(b)
+	is.NoError(err)
+
+	val, _ := out.Get(p.key)
+	sctx, ok := val.([]Context)
+	is.True(ok)
+	is.Len(sctx, 2)
+
+	val, _ = sctx[1].Get("B")
+	is.Equal("B", val)
+	val, _ = sctx[1].Get("foo")
+	is.Equal("bar", val, "sub-contexts should be linked to their decoded parent")
+	_, found := sctx[0].Get("B")
+	is.False(found)
+
+	cmdA := &MockCommand{name: "A"}
+	cmdB := &MockCommand{name: "B"}
+	(&parallel{key: p.key, cmds: []Command{cmdA, cmdB}}).Rollback(out, DefaultPrinter)
+	is.True(cmdA.rolledBack, "decoded contexts should support rollback")
+	is.True(cmdB.rolledBack)
+}
+
+func TestMarshalContext_Unserializable(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+
+	ctx := NewContext()
+	ctx.Set("ok", "foo")
+	ctx.Set("buf", &bytes.Buffer{})
+	ctx.Set(struct{}{}, "bar")
+
+	b, err := MarshalContext(ctx)
+	is.Nil(b)
+
+	uerr, ok := err.(*UnserializableError)
+	is.True(ok)
+	is.Len(uerr.Entries, 2)
+	is.Contains(uerr.Error(), "buf")
+	is.Contains(uerr.Error(), "*bytes.Buffer")
+}
+
+func TestUnmarshalContext_Errors(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+
+	_, err := UnmarshalContext([]byte("{"))
+	is.Error(err)
+
+	_, err = UnmarshalContext([]byte(`{"layers":[[{"key":{"type":"unknown"},"value":{"type":"nil"}}]]}`))
+	is.Error(err)
+
+	_, err = UnmarshalContext([]byte(`{"layers":[[{"key":{"type":"bytes","value":"AA=="},"value":{"type":"nil"}}]]}`))
+	is.Error(err, "keys must be comparable")
+
+	ctx, err := UnmarshalContext([]byte(`{"layers":[]}`))
+	is.NoError(err)
+	ctx.Set("foo", "bar")
+}
+
+func TestMarshalContext_ScopedKeys(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	ns := namespace.New("foo")
+
+	ctx := NewContext()
+	newScopedContext(ctx, "a").Set(ns.Key("bar"), "baz")
+
+	b, err := MarshalContext(ctx)
+	is.NoError(err)
+
+	out, err := UnmarshalContext(b)
+	is.NoError(err)
+
+	val, _ := newScopedContext(out, "a").Get(ns.Key("bar"))
+	is.Equal("baz", val)
+}
+
+func TestMarshalContext_Race(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+
+	winner := &MockCommand{name: "A", set: "out", see: "out"}
+	loser := &MockCommand{name: "B", err: errors.New("foo")}
+	race := Race(winner, loser)
+
+	ctx := NewContext()
+	race.Run(ctx, DefaultPrinter)
+	is.NoError(ctx.Err())
+
+	b, err := MarshalContext(ctx)
+	is.NoError(err, "contexts that ran a Race should be serializable")
+
+	out, err := UnmarshalContext(b)
+	is.NoError(err)
+
+	val, _ := out.Get("out")
+	is.Equal("A", val)
+
+	race.(Rollbacker).Rollback(out, DefaultPrinter)
+	is.True(winner.rolledBack, "the winner should be rolled back from the decoded Context")
+	is.True(winner.seenVal, "the winner should see its own values when rolled back")
+	is.False(loser.rolledBack)
+}
+
+func TestMarshalContext_CommandInstances(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+
+	cmd := &MockCommand{name: "A"}
+	par := MakeParallel(cmd)
+
+	ctx := NewContext()
+	par.Run(ctx, DefaultPrinter)
+
+	b, err := MarshalContext(ctx)
+	is.NoError(err)
+
+	out, err := UnmarshalContext(b)
+	is.NoError(err)
+	par.(Rollbacker).Rollback(out, DefaultPrinter)
+	is.True(cmd.rolledBack, "the same Command instance should find its state")
+
+	out, err = UnmarshalContext(b)
+	is.NoError(err)
+	is.Panics(func() {
+		MakeParallel(&MockCommand{name: "A"}).(Rollbacker).Rollback(out, DefaultPrinter)
+	}, "identical Commands constructed again should not find the state")
+}
@@ -0,0 +1,86 @@
+package runner
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+var (
+	// shutdownSignals are the signals handled by RunWithSignals.
+	shutdownSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+
+	// exit is called when a second signal is received; replaced in tests.
+	exit = os.Exit
+)
+
+// SignalError is returned by RunWithSignals when the run was interrupted by a signal and rolled back.
+type SignalError struct {
+	Signal os.Signal
+}
+
+func (e *SignalError) Error() string {
+	return fmt.Sprintf("interrupted by signal: %v", e.Signal)
+}
+
+// RunWithSignals executes the passed in Commands in sequence like RunWithPrinter, while handling SIGINT and SIGTERM.
+//
+// On the first signal, the root Context is canceled: no new Commands are scheduled, in-flight Commands are allowed to
+// complete, and every Command that ran is rolled back. A SignalError naming the signal is then returned. A second
+// signal aborts the process immediately, without completing the rollback.
+func RunWithSignals(p Printer, cmds ...Command) error {
+	return Execute(cmds, WithPrinter(p), WithSignals())
+}
+
+// signalHandler cancels a Context on the first shutdown signal and exits the process on the second.
+type signalHandler struct {
+	sigs chan os.Signal
+	done chan struct{}
+
+	mu  sync.Mutex
+	sig os.Signal
+}
+
+func handleSignals(ctx Context, p Printer) *signalHandler {
+	h := &signalHandler{
+		sigs: make(chan os.Signal, 1),
+		done: make(chan struct{}),
+	}
+	signal.Notify(h.sigs, shutdownSignals...)
+
+	go func() {
+		select {
+		case sig := <-h.sigs:
+			p.Warn("received %v: stopping and rolling back, signal again to abort immediately", sig)
+			h.mu.Lock()
+			h.sig = sig
+			h.mu.Unlock()
+			ctx.cancel()
+		case <-h.done:
+			return
+		}
+
+		select {
+		case sig := <-h.sigs:
+			p.Fatal("received %v: aborting", sig)
+			exit(1)
+		case <-h.done:
+		}
+	}()
+
+	return h
+}
+
+// received returns the first signal received, if any.
+func (h *signalHandler) received() os.Signal {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.sig
+}
+
+func (h *signalHandler) stop() {
+	signal.Stop(h.sigs)
+	close(h.done)
+}
@@ -0,0 +1,85 @@
+package runner
+
+import (
+	"errors"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type signalCommand struct {
+	signal syscall.Signal
+	ran    bool
+}
+
+func (c *signalCommand) Run(ctx Context, p Printer) {
+	c.ran = true
+	_ = syscall.Kill(syscall.Getpid(), c.signal)
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		ctx.SetErr(errors.New("signal not handled"))
+	}
+}
+
+func TestSignalError(t *testing.T) {
+	assert.Contains(t, (&SignalError{Signal: syscall.SIGTERM}).Error(), "terminated")
+}
+
+func TestRunWithSignals_Success(t *testing.T) {
+	is := assert.New(t)
+	cmd := &MockCommand{name: "A"}
+
+	is.NoError(RunWithSignals(DefaultPrinter, cmd))
+	is.True(cmd.ran)
+}
+
+func TestRunWithSignals_Failure(t *testing.T) {
+	err := errors.New("foo")
+	assert.Equal(t, err, RunWithSignals(DefaultPrinter, &MockCommand{name: "A", err: err}))
+}
+
+func TestRunWithSignals_Interrupt(t *testing.T) {
+	is := assert.New(t)
+
+	cmdA := &MockCommand{name: "A"}
+	cmdB := &signalCommand{signal: syscall.SIGINT}
+	cmdC := &MockCommand{name: "C"}
+
+	err := RunWithSignals(DefaultPrinter, cmdA, cmdB, cmdC)
+	is.Equal(&SignalError{Signal: syscall.SIGINT}, err)
+
+	is.True(cmdB.ran, "in-flight command should complete")
+	is.True(cmdA.rolledBack, "previous commands should be rolled back")
+	is.False(cmdC.ran, "no new commands should be scheduled")
+}
+
+func TestRunWithSignals_Abort(t *testing.T) {
+	is := assert.New(t)
+
+	codes := make(chan int, 1)
+	exit = func(code int) { codes <- code }
+	defer func() { exit = os.Exit }()
+
+	var code int
+	cmd := commandFunc(func(ctx Context, p Printer) {
+		_ = syscall.Kill(syscall.Getpid(), syscall.SIGTERM)
+		<-ctx.Done()
+		_ = syscall.Kill(syscall.Getpid(), syscall.SIGTERM)
+
+		select {
+		case code = <-codes:
+		case <-time.After(time.Second):
+		}
+	})
+
+	err := RunWithSignals(DefaultPrinter, MakeParallel(cmd))
+	is.Equal(&SignalError{Signal: syscall.SIGTERM}, err)
+	is.Equal(1, code, "second signal should abort")
+
+	is.NoError(RunWithSignals(DefaultPrinter, &MockCommand{name: "A"}), "handlers should be removed after the run")
+}
@@ -0,0 +1,209 @@
+//go:build go1.21
+
+package runner
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+)
+
+// slog levels used for the LogLevels that have no slog equivalent.
+const (
+	SlogLevelTrace = slog.Level(-8)
+	SlogLevelFatal = slog.Level(12)
+)
+
+// SlogPrefixKey is the attribute under which a Printer returned by NewSlogPrinter records the prefixes added via
+// WithPrefix.
+const SlogPrefixKey = "prefix"
+
+// NewSlogPrinter returns a Printer that writes all logs to l. LogLevels are mapped to the slog level of the same name,
+// with Trace and Fatal mapped to SlogLevelTrace and SlogLevelFatal, respectively; filtering is left to l's Handler.
+// Prefixes are concatenated and recorded as the SlogPrefixKey attribute rather than prepended to the message, and
+// Fields are recorded as attributes.
+func NewSlogPrinter(l *slog.Logger) Printer {
+	return &slogPrinter{logger: l}
+}
+
+type slogPrinter struct {
+	logger *slog.Logger
+	prefix string
+	fields Fields
+}
+
+func (p *slogPrinter) Log(level LogLevel, format string, values ...interface{}) {
+	p.LogFields(level, nil, format, values...)
+}
+
+func (p *slogPrinter) LogFields(level LogLevel, fields Fields, format string, values ...interface{}) {
+	lvl := slogLevel(level)
+	if !p.logger.Enabled(context.Background(), lvl) {
+		return
+	}
+
+	attrs := fieldAttrs(mergeFields(p.fields, fields))
+	if p.prefix != "" {
+		attrs = append(attrs, slog.String(SlogPrefixKey, p.prefix))
+	}
+	p.logger.LogAttrs(context.Background(), lvl, sprintf(format, values...), attrs...)
+}
+
+func (p *slogPrinter) Trace(format string, values ...interface{}) {
+	p.Log(LevelTrace, format, values...)
+}
+
+func (p *slogPrinter) Debug(format string, values ...interface{}) {
+	p.Log(LevelDebug, format, values...)
+}
+
+func (p *slogPrinter) Info(format string, values ...interface{}) {
+	p.Log(LevelInfo, format, values...)
+}
+
+func (p *slogPrinter) Warn(format string, values ...interface{}) {
+	p.Log(LevelWarn, format, values...)
+}
+
+func (p *slogPrinter) Err(format string, values ...interface{}) {
+	p.Log(LevelError, format, values...)
+}
+
+func (p *slogPrinter) Fatal(format string, values ...interface{}) {
+	p.Log(LevelFatal, format, values...)
+}
+
+func (p *slogPrinter) WithPrefix(prefix string) Printer {
+	return &slogPrinter{
+		logger: p.logger,
+		prefix: p.prefix + prefix,
+		fields: p.fields,
+	}
+}
+
+func (p *slogPrinter) WithFields(fields Fields) Printer {
+	return &slogPrinter{
+		logger: p.logger,
+		prefix: p.prefix,
+		fields: mergeFields(p.fields, fields),
+	}
+}
+
+func (p *slogPrinter) enabled(level LogLevel) bool {
+	return p.logger.Enabled(context.Background(), slogLevel(level))
+}
+
+// NewSlogHandler returns a slog.Handler that writes records into p, allowing code that logs via log/slog to appear in
+// the output of a run. slog levels are mapped to the nearest LogLevel at or below them, and attributes are passed as
+// Fields if p is a FieldPrinter (see AddFields). Attributes within groups are named by their dot-separated group path.
+func NewSlogHandler(p Printer) slog.Handler {
+	return &slogHandler{printer: p}
+}
+
+type slogHandler struct {
+	printer Printer
+	fields  Fields
+	group   string
+}
+
+func (h *slogHandler) Enabled(context.Context, slog.Level) bool {
+	return true
+}
+
+func (h *slogHandler) Handle(_ context.Context, r slog.Record) error {
+	fields := make(Fields, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		addAttr(fields, h.group, a)
+		return true
+	})
+
+	AddFields(h.printer, mergeFields(h.fields, fields)).Log(printerLevel(r.Level), "%s", r.Message)
+	return nil
+}
+
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	fields := make(Fields, len(attrs))
+	for _, a := range attrs {
+		addAttr(fields, h.group, a)
+	}
+
+	return &slogHandler{
+		printer: h.printer,
+		fields:  mergeFields(h.fields, fields),
+		group:   h.group,
+	}
+}
+
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	return &slogHandler{
+		printer: h.printer,
+		fields:  h.fields,
+		group:   h.group + name + ".",
+	}
+}
+
+func slogLevel(level LogLevel) slog.Level {
+	switch {
+	case level <= LevelTrace:
+		return SlogLevelTrace
+	case level == LevelDebug:
+		return slog.LevelDebug
+	case level == LevelInfo:
+		return slog.LevelInfo
+	case level == LevelWarn:
+		return slog.LevelWarn
+	case level == LevelError:
+		return slog.LevelError
+	}
+	return SlogLevelFatal
+}
+
+func printerLevel(level slog.Level) LogLevel {
+	switch {
+	case level < slog.LevelDebug:
+		return LevelTrace
+	case level < slog.LevelInfo:
+		return LevelDebug
+	case level < slog.LevelWarn:
+		return LevelInfo
+	case level < slog.LevelError:
+		return LevelWarn
+	case level < SlogLevelFatal:
+		return LevelError
+	}
+	return LevelFatal
+}
+
+// fieldAttrs converts fields to attributes, sorted by key.
+func fieldAttrs(fields Fields) []slog.Attr {
+	attrs := make([]slog.Attr, 0, len(fields))
+	for k, v := range fields {
+		attrs = append(attrs, slog.Any(k, v))
+	}
+	sort.Slice(attrs, func(i, j int) bool { return attrs[i].Key < attrs[j].Key })
+	return attrs
+}
+
+// addAttr adds a to fields, flattening groups into dot-separated keys.
+func addAttr(fields Fields, prefix string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+
+	if a.Value.Kind() != slog.KindGroup {
+		fields[prefix+a.Key] = a.Value.Any()
+		return
+	}
+
+	if a.Key != "" {
+		prefix += a.Key + "."
+	}
+	for _, ga := range a.Value.Group() {
+		addAttr(fields, prefix, ga)
+	}
+}
@@ -0,0 +1,122 @@
+//go:build go1.21
+
+package runner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getSlogTestPrinter(level slog.Level) (Printer, *bytes.Buffer) {
+	buf := &bytes.Buffer{}
+	h := slog.NewJSONHandler(buf, &slog.HandlerOptions{
+		Level: level,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})
+	return NewSlogPrinter(slog.New(h)), buf
+}
+
+func decodeSlog(t *testing.T, buf *bytes.Buffer) map[string]interface{} {
+	out := map[string]interface{}{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &out))
+	buf.Reset()
+	return out
+}
+
+func TestSlogPrinter_Levels(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	p, buf := getSlogTestPrinter(SlogLevelTrace)
+
+	tests := []struct {
+		log  func(string, ...interface{})
+		want string
+	}{
+		{p.Trace, "DEBUG-4"},
+		{p.Debug, "DEBUG"},
+		{p.Info, "INFO"},
+		{p.Warn, "WARN"},
+		{p.Err, "ERROR"},
+		{p.Fatal, "ERROR+4"},
+	}
+
+	for _, test := range tests {
+		test.log("foo%s", "bar")
+		out := decodeSlog(t, buf)
+		is.Equal(test.want, out["level"])
+		is.Equal("foobar", out["msg"])
+	}
+
+	p, buf = getSlogTestPrinter(slog.LevelInfo)
+	p.Debug("foo")
+	is.Empty(buf.String(), "filtering should be left to the handler")
+	is.False(traceEnabled(p), "the handler should decide whether tracing is enabled")
+
+	p, _ = getSlogTestPrinter(SlogLevelTrace)
+	is.True(traceEnabled(p.WithPrefix("foo")))
+}
+
+func TestSlogPrinter_PrefixFields(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	p, buf := getSlogTestPrinter(slog.LevelInfo)
+
+	AddFields(p.WithPrefix("foo: "), Fields{"fizz": "buzz"}).WithPrefix("bar: ").Info("baz")
+	is.Equal(map[string]interface{}{
+		"level":  "INFO",
+		"msg":    "baz",
+		"prefix": "foo: bar: ",
+		"fizz":   "buzz",
+	}, decodeSlog(t, buf))
+}
+
+func TestSlogPrinter_Fields_Duplicates(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	p, buf := getSlogTestPrinter(slog.LevelInfo)
+
+	fp := AddFields(AddFields(p, Fields{"foo": "bar", "fizz": "buzz"}), Fields{"foo": "baz"})
+	fp.(FieldPrinter).LogFields(LevelInfo, Fields{"fizz": "bang"}, "msg")
+	is.Equal(`{"level":"INFO","msg":"msg","fizz":"bang","foo":"baz"}`+"\n", buf.String(),
+		"each key should be recorded once, with inner fields taking precedence")
+}
+
+func TestSlogHandler(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	p, out := getTestPrinter()
+	p.level = LevelDebug
+
+	l := slog.New(NewSlogHandler(p)).With("foo", "bar").WithGroup("g")
+	l.Info("100%", "fizz", 1, slog.Group("h", "buzz", true))
+	is.Equal("100% foo=bar g.fizz=1 g.h.buzz=true\n", out.String())
+	out.Reset()
+
+	l.Log(context.Background(), slog.Level(-8), "trace")
+	is.Empty(out.String(), "levels below Debug should map to Trace")
+
+	l.Warn("warn")
+	is.Equal("warn foo=bar\n", out.String())
+}
+
+func TestPrinterLevel(t *testing.T) {
+	t.Parallel()
+
+	for _, level := range []LogLevel{LevelTrace, LevelDebug, LevelInfo, LevelWarn, LevelError, LevelFatal} {
+		assert.Equal(t, level, printerLevel(slogLevel(level)))
+	}
+}
@@ -0,0 +1,99 @@
+package runner
+
+// store is the layered key-value storage of a root or sub-context. Rather than a map per layer, which must be scanned
+// from the top layer down on every lookup, each key maps to the stack of values set for it, one per layer that set the
+// key. Lookups are therefore O(1) regardless of the number of layers. Each layer also records the keys set within it,
+// so pushing a layer is O(1) and popping one only touches the keys it set.
+//
+// A store is not safe for concurrent use; the owning Context is responsible for locking.
+type store struct {
+	vals map[interface{}][]entry
+	keys [][]interface{}
+}
+
+// entry is a value set for a key in a single layer of a store.
+type entry struct {
+	layer int
+	val   interface{}
+	prov  Provenance
+}
+
+func newStore() store {
+	return store{
+		vals: make(map[interface{}][]entry),
+		keys: make([][]interface{}, 1),
+	}
+}
+
+// get returns the entry for key in the topmost layer that set it.
+func (s *store) get(key interface{}) (e entry, found bool) {
+	es := s.vals[key]
+	if len(es) == 0 {
+		return entry{}, false
+	}
+	return es[len(es)-1], true
+}
+
+// set stores val for key in the top layer, shadowing any values set for key in lower layers.
+func (s *store) set(key, val interface{}, prov Provenance) {
+	top := len(s.keys) - 1
+	es := s.vals[key]
+
+	if n := len(es); n > 0 && es[n-1].layer == top {
+		es[n-1].val, es[n-1].prov = val, prov
+		return
+	}
+
+	s.vals[key] = append(es, entry{layer: top, val: val, prov: prov})
+	s.keys[top] = append(s.keys[top], key)
+}
+
+func (s *store) push() {
+	s.keys = append(s.keys, nil)
+}
+
+func (s *store) pop() {
+	top := len(s.keys) - 1
+	if top == 0 {
+		panic("cannot pop root context")
+	}
+
+	for _, k := range s.keys[top] {
+		es := s.vals[k]
+		if len(es) == 1 {
+			delete(s.vals, k)
+			continue
+		}
+		es[len(es)-1] = entry{}
+		s.vals[k] = es[:len(es)-1]
+	}
+
+	s.keys[top] = nil
+	s.keys = s.keys[:top]
+}
+
+// locals returns the visible value of every key in the store.
+func (s *store) locals() hash {
+	out := make(hash, len(s.vals))
+	for k, es := range s.vals {
+		out[k] = es[len(es)-1].val
+	}
+	return out
+}
+
+// layers returns a copy of each layer's key-value pairs, from the bottom layer up.
+func (s *store) layers() []layer {
+	out := make([]layer, len(s.keys))
+	for i, keys := range s.keys {
+		out[i] = layer{kvs: make(hash, len(keys)), prov: make(provs, len(keys))}
+	}
+
+	for k, es := range s.vals {
+		for _, e := range es {
+			out[e.layer].kvs[k] = e.val
+			out[e.layer].prov[k] = e.prov
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,135 @@
+package runner
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStore_Shadowing(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	s := newStore()
+	s.set("foo", "a", Provenance{Command: "A"})
+	s.push()
+	s.set("foo", "b", Provenance{Command: "B"})
+	s.set("foo", "c", Provenance{Command: "C"})
+	s.set("bar", "d", Provenance{})
+	s.push()
+	s.push()
+
+	e, found := s.get("foo")
+	is.True(found)
+	is.Equal("c", e.val, "values should be visible through empty layers")
+	is.Equal("C", e.prov.Command)
+
+	layers := s.layers()
+	is.Len(layers, 4)
+	is.Equal(hash{"foo": "a"}, layers[0].kvs)
+	is.Equal(hash{"foo": "c", "bar": "d"}, layers[1].kvs, "resetting a key in the same layer should replace it")
+	is.Empty(layers[2].kvs)
+	is.Equal(hash{"foo": "c", "bar": "d"}, s.locals())
+
+	s.pop()
+	s.pop()
+	s.pop()
+
+	e, _ = s.get("foo")
+	is.Equal("a", e.val, "popping should restore shadowed values")
+	_, found = s.get("bar")
+	is.False(found, "popping should remove keys only set in the popped layer")
+	is.Panics(s.pop, "root layer should not be popped")
+}
+
+// sliceStore is the previous Context storage, which scans a map per layer from the top down on every lookup. It is
+// retained as a baseline for the store benchmarks.
+type sliceStore []hash
+
+func (s *sliceStore) get(key interface{}) (val interface{}, found bool) {
+	for i := len(*s) - 1; i >= 0; i-- {
+		if val, found = (*s)[i][key]; found {
+			break
+		}
+	}
+	return
+}
+
+func (s *sliceStore) set(key, val interface{}) { (*s)[len(*s)-1][key] = val }
+func (s *sliceStore) push()                    { *s = append(*s, make(hash)) }
+func (s *sliceStore) pop()                     { *s = (*s)[:len(*s)-1] }
+
+var storeDepths = []int{1, 10, 100, 1000}
+
+func BenchmarkStore_Get(b *testing.B) {
+	for _, depth := range storeDepths {
+		b.Run(fmt.Sprintf("depth=%d", depth), func(b *testing.B) {
+			s := newStore()
+			s.set("root", 0, Provenance{})
+			for i := 1; i < depth; i++ {
+				s.push()
+				s.set(i, i, Provenance{})
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				s.get("root")
+			}
+		})
+	}
+}
+
+func BenchmarkSliceStore_Get(b *testing.B) {
+	for _, depth := range storeDepths {
+		b.Run(fmt.Sprintf("depth=%d", depth), func(b *testing.B) {
+			s := sliceStore{make(hash)}
+			s.set("root", 0)
+			for i := 1; i < depth; i++ {
+				s.push()
+				s.set(i, i)
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				s.get("root")
+			}
+		})
+	}
+}
+
+func BenchmarkStore_PushSetPop(b *testing.B) {
+	s := newStore()
+	for i := 0; i < b.N; i++ {
+		s.push()
+		s.set("foo", i, Provenance{})
+		s.pop()
+	}
+}
+
+func BenchmarkSliceStore_PushSetPop(b *testing.B) {
+	s := sliceStore{make(hash)}
+	for i := 0; i < b.N; i++ {
+		s.push()
+		s.set("foo", i)
+		s.pop()
+	}
+}
+
+func BenchmarkContext_Get(b *testing.B) {
+	for _, depth := range storeDepths {
+		b.Run(fmt.Sprintf("depth=%d", depth), func(b *testing.B) {
+			ctx := NewContext()
+			ctx.Set("root", 0)
+			for i := 1; i < depth; i++ {
+				ctx.push()
+				ctx.Set(i, i)
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				ctx.Get("root")
+			}
+		})
+	}
+}
@@ -3,12 +3,31 @@ package runner
 type subCtx struct {
 	parent Context
 	ctx    Context
+
+	// detach stops propagating cancellation from the parent, releasing the parent's reference to the sub-context.
+	detach func()
 }
 
 func newSubContext(parent Context) Context {
-	return &subCtx{
+	c := NewContext().(*ctx)
+	c.cmds = parent.path()
+	c.stack = parent.trace()
+
+	sc := &subCtx{
 		parent: parent,
-		ctx:    NewContext(),
+		ctx:    c,
+	}
+	sc.detach = parent.onCancel(sc.cancel)
+	return sc
+}
+
+// detachSubContexts stops propagating cancellation to sctx from their parent. It should be called once the Commands
+// executing with sctx have returned, so the parent does not retain them for the rest of the run.
+func detachSubContexts(sctx []Context) {
+	for _, c := range sctx {
+		if sc, ok := c.(*subCtx); ok {
+			sc.detach()
+		}
 	}
 }
 
@@ -31,6 +50,13 @@ func (sc *subCtx) Set(key, val interface{}) {
 	sc.ctx.Set(key, val)
 }
 
+func (sc *subCtx) Provenance(key interface{}) (prov Provenance, found bool) {
+	if prov, found = sc.ctx.Provenance(key); !found {
+		prov, found = sc.parent.Provenance(key)
+	}
+	return
+}
+
 func (sc *subCtx) push() {
 	sc.ctx.push()
 }
@@ -42,3 +68,75 @@ func (sc *subCtx) pop() {
 func (sc *subCtx) unsetErr() {
 	sc.ctx.unsetErr()
 }
+
+func (sc *subCtx) Done() <-chan struct{} {
+	return sc.ctx.Done()
+}
+
+func (sc *subCtx) cancel() {
+	sc.ctx.cancel()
+}
+
+func (sc *subCtx) onCancel(fn func()) (remove func()) {
+	return sc.ctx.onCancel(fn)
+}
+
+func (sc *subCtx) locals() hash {
+	return sc.ctx.locals()
+}
+
+func (sc *subCtx) layers() []layer {
+	parent := sc.parent.layers()
+
+	fork := 1
+	if len(parent) > 0 {
+		fork = parent[len(parent)-1].fork + 1
+	}
+
+	own := sc.ctx.layers()
+	for i := range own {
+		own[i].fork = fork
+	}
+
+	return append(parent, own...)
+}
+
+func (sc *subCtx) locks() *lockRegistry {
+	return sc.parent.locks()
+}
+
+func (sc *subCtx) enter(cmd interface{}) {
+	sc.ctx.enter(cmd)
+}
+
+func (sc *subCtx) leave() {
+	sc.ctx.leave()
+}
+
+func (sc *subCtx) path() []string {
+	return sc.ctx.path()
+}
+
+func (sc *subCtx) trace() []interface{} {
+	return sc.ctx.trace()
+}
+
+func (sc *subCtx) self() Context {
+	return sc
+}
+
+func (sc *subCtx) observe(o Observer) {
+	sc.parent.observe(o)
+}
+
+func (sc *subCtx) notify(e Event) {
+	sc.parent.notify(e)
+}
+
+func (sc *subCtx) attempt(cmd interface{}, path string) int {
+	return sc.parent.attempt(cmd, path)
+}
+
+func (sc *subCtx) attempted(cmd interface{}, path string, failed bool) {
+	sc.parent.attempted(cmd, path, failed)
+}
@@ -83,3 +83,54 @@ func TestSubContext_GetSet(t *testing.T) {
 	_, found := ctx.Get(unknown)
 	is.False(found, "parent should not see new key from subcontext")
 }
+
+func TestSubContext_Cancel(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+
+	ctx := NewContext()
+	sctx1 := newSubContext(ctx)
+	sctx2 := newSubContext(ctx)
+
+	sctx1.cancel()
+	_, open := <-sctx1.Done()
+	is.False(open, "canceled subcontext should be done")
+
+	select {
+	case <-ctx.Done():
+		is.Fail("parent should not be canceled by subcontext")
+	case <-sctx2.Done():
+		is.Fail("sibling should not be canceled by subcontext")
+	default:
+	}
+
+	ctx.cancel()
+	_, open = <-sctx2.Done()
+	is.False(open, "canceling the parent should cancel the subcontext")
+}
+
+func TestSubContext_Locals(t *testing.T) {
+	t.Parallel()
+
+	ctx := NewContext()
+	ctx.Set("foo", "bar")
+
+	sctx := newSubContext(ctx)
+	sctx.Set("fizz", "buzz")
+
+	assert.Equal(t, hash{"fizz": "buzz"}, sctx.locals(), "subcontext locals should exclude the parent")
+}
+
+func TestSubContext_Detach(t *testing.T) {
+	t.Parallel()
+	is := assert.New(t)
+
+	root := NewContext()
+	MakeParallel(&MockCommand{name: "A"}, &MockCommand{name: "B"}).Run(root, DefaultPrinter)
+	Race(&MockCommand{name: "C"}, &MockCommand{name: "D"}).Run(root, DefaultPrinter)
+	MakeQuorum(1, &MockCommand{name: "E"}).Run(root, DefaultPrinter)
+
+	is.NoError(root.Err())
+	is.Empty(root.(*ctx).onCancels, "finished sub-contexts should be released by their parent")
+}
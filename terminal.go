@@ -0,0 +1,231 @@
+package runner
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultTimestampLayout is the time layout used by a TerminalPrinter when timestamps are enabled with an empty layout.
+const DefaultTimestampLayout = "15:04:05.000"
+
+// A TerminalPrinter describes the optional configuration methods available on the Printer returned by
+// NewTerminalPrinter. These methods mutate the underlying output, shared by all Printers derived from it via WithPrefix
+// and AddFields, and should be called before logging; the Printer is passed through for chaining convenience.
+type TerminalPrinter interface {
+	FieldPrinter
+
+	// SetColor specifies if level badges should be colorized using ANSI escape codes. By default, colors are enabled if
+	// the output is a terminal (see Progress) and the NO_COLOR environment variable is empty.
+	SetColor(color bool) TerminalPrinter
+
+	// SetTimestamps specifies if each message should be prefixed by the time it was logged, formatted with layout. An
+	// empty layout uses DefaultTimestampLayout. Timestamps are disabled by default.
+	SetTimestamps(enabled bool, layout string) TerminalPrinter
+
+	// SetElapsed specifies if each message should be prefixed by the time elapsed since the Printer was created.
+	// Elapsed times are disabled by default.
+	SetElapsed(enabled bool) TerminalPrinter
+}
+
+// NewTerminalPrinter returns a Printer intended for interactive use, which writes all logs to the provided io.Writer.
+// LogLevels below the provided level are suppressed from output. Each message is preceded by a badge naming its
+// LogLevel, and messages logged by Commands are indented by how deeply the Command is nested within sequences and
+// parallel Commands (see FieldCommand).
+//
+// Fields are appended to each message as key=value pairs, except FieldCommand, which is conveyed by the indentation,
+// and FieldPhase and FieldAttempt, which are only shown when the phase is not PhaseRun or the attempt is not the first.
+func NewTerminalPrinter(w io.Writer, level LogLevel) TerminalPrinter {
+	return &termPrinter{
+		out: &termOutput{
+			w:     w,
+			level: level,
+			color: isTerminal(w) && os.Getenv("NO_COLOR") == "",
+			start: time.Now(),
+			now:   time.Now,
+		},
+	}
+}
+
+// isTerminal reports whether w is a character device, such as a terminal, or reports itself to be one via an
+// IsTerminal method.
+func isTerminal(w io.Writer) bool {
+	if t, ok := w.(interface{ IsTerminal() bool }); ok {
+		return t.IsTerminal()
+	}
+
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+const ansiReset = "\x1b[0m"
+
+var levelBadges = map[LogLevel]struct{ text, color string }{
+	LevelTrace: {"TRC", "\x1b[90m"},
+	LevelDebug: {"DBG", "\x1b[36m"},
+	LevelInfo:  {"INF", "\x1b[32m"},
+	LevelWarn:  {"WRN", "\x1b[33m"},
+	LevelError: {"ERR", "\x1b[31m"},
+	LevelFatal: {"FTL", "\x1b[1;41m"},
+}
+
+type termOutput struct {
+	sync.Mutex
+	w     io.Writer
+	level LogLevel
+
+	color      bool
+	timestamps string
+	elapsed    bool
+
+	start time.Time
+	now   func() time.Time
+}
+
+type termPrinter struct {
+	out    *termOutput
+	prefix string
+	fields Fields
+}
+
+func (p *termPrinter) Log(level LogLevel, format string, values ...interface{}) {
+	p.LogFields(level, nil, format, values...)
+}
+
+func (p *termPrinter) LogFields(level LogLevel, fields Fields, format string, values ...interface{}) {
+	if level < p.out.level {
+		return
+	}
+
+	fields = mergeFields(p.fields, fields)
+	now := p.out.now()
+
+	buf := &bytes.Buffer{}
+	if p.out.timestamps != "" {
+		buf.WriteString(now.Format(p.out.timestamps))
+		buf.WriteByte(' ')
+	}
+	if p.out.elapsed {
+		fmt.Fprintf(buf, "+%.3fs ", now.Sub(p.out.start).Seconds())
+	}
+
+	badge, ok := levelBadges[level]
+	if !ok {
+		badge.text = "???"
+	}
+	if p.out.color && badge.color != "" {
+		buf.WriteString(badge.color + badge.text + ansiReset)
+	} else {
+		buf.WriteString(badge.text)
+	}
+	buf.WriteByte(' ')
+
+	buf.WriteString(strings.Repeat("  ", commandDepth(fields)))
+	buf.WriteString(p.prefix)
+	buf.WriteString(sprintf(format, values...))
+	buf.WriteString(formatFields(terminalFields(fields)))
+	buf.WriteByte('\n')
+
+	p.out.Lock()
+	_, _ = p.out.w.Write(buf.Bytes())
+	p.out.Unlock()
+}
+
+func (p *termPrinter) Trace(format string, values ...interface{}) {
+	p.Log(LevelTrace, format, values...)
+}
+
+func (p *termPrinter) Debug(format string, values ...interface{}) {
+	p.Log(LevelDebug, format, values...)
+}
+
+func (p *termPrinter) Info(format string, values ...interface{}) {
+	p.Log(LevelInfo, format, values...)
+}
+
+func (p *termPrinter) Warn(format string, values ...interface{}) {
+	p.Log(LevelWarn, format, values...)
+}
+
+func (p *termPrinter) Err(format string, values ...interface{}) {
+	p.Log(LevelError, format, values...)
+}
+
+func (p *termPrinter) Fatal(format string, values ...interface{}) {
+	p.Log(LevelFatal, format, values...)
+}
+
+func (p *termPrinter) WithPrefix(prefix string) Printer {
+	return &termPrinter{
+		out:    p.out,
+		prefix: p.prefix + prefix,
+		fields: p.fields,
+	}
+}
+
+func (p *termPrinter) WithFields(fields Fields) Printer {
+	return &termPrinter{
+		out:    p.out,
+		prefix: p.prefix,
+		fields: mergeFields(p.fields, fields),
+	}
+}
+
+func (p *termPrinter) enabled(level LogLevel) bool {
+	return level >= p.out.level
+}
+
+func (p *termPrinter) SetColor(color bool) TerminalPrinter {
+	p.out.color = color
+	return p
+}
+
+func (p *termPrinter) SetTimestamps(enabled bool, layout string) TerminalPrinter {
+	switch {
+	case !enabled:
+		p.out.timestamps = ""
+	case layout == "":
+		p.out.timestamps = DefaultTimestampLayout
+	default:
+		p.out.timestamps = layout
+	}
+	return p
+}
+
+func (p *termPrinter) SetElapsed(enabled bool) TerminalPrinter {
+	p.out.elapsed = enabled
+	return p
+}
+
+// commandDepth returns how many Commands the executing Command is nested within, based on its FieldCommand.
+func commandDepth(fields Fields) int {
+	path, ok := fields[FieldCommand].(string)
+	if !ok || path == "" {
+		return 0
+	}
+	return strings.Count(path, commandPathSep)
+}
+
+// terminalFields returns fields without those conveyed by the layout of a TerminalPrinter's output.
+func terminalFields(fields Fields) Fields {
+	out := make(Fields, len(fields))
+	for k, v := range fields {
+		switch {
+		case k == FieldCommand:
+		case k == FieldPhase && v == PhaseRun.String():
+		case k == FieldAttempt && v == 1:
+		default:
+			out[k] = v
+		}
+	}
+	return out
+}
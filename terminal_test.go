@@ -0,0 +1,93 @@
+package runner
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getTerminalTestPrinter(level LogLevel) (*termPrinter, *bytes.Buffer) {
+	buf := &bytes.Buffer{}
+	p := NewTerminalPrinter(buf, level).(*termPrinter)
+	p.out.start = time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	p.out.now = func() time.Time { return p.out.start.Add(1500 * time.Millisecond) }
+	return p, buf
+}
+
+func TestTerminalPrinter_Plain(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	p, out := getTerminalTestPrinter(LevelInfo)
+	is.False(p.out.color, "non-terminal writers should not be colorized")
+
+	p.Debug("foo")
+	is.Empty(out.String())
+
+	p.Warn("foo%s", "bar")
+	p.WithPrefix("pre: ").Info("baz")
+	is.Equal("WRN foobar\nINF pre: baz\n", out.String())
+}
+
+func TestTerminalPrinter_Options(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	p, out := getTerminalTestPrinter(LevelAll)
+
+	p.SetColor(true).SetTimestamps(true, "").SetElapsed(true).Err("foo")
+	is.Equal("03:04:06.500 +1.500s \x1b[31mERR\x1b[0m foo\n", out.String())
+	out.Reset()
+
+	p.SetColor(false).SetTimestamps(false, "").SetElapsed(false).Fatal("foo")
+	is.Equal("FTL foo\n", out.String())
+}
+
+func TestTerminalPrinter_Indentation(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	p, out := getTerminalTestPrinter(LevelInfo)
+
+	err := RunWithPrinter(p, MakeParallel(&MockCommand{name: "A"}), &MockCommand{name: "B"})
+	is.NoError(err)
+	is.Equal("INF   MOCK running A\nINF MOCK running B\n", out.String())
+	out.Reset()
+
+	AddFields(p, Fields{FieldCommand: "A", FieldPhase: "rollback", FieldAttempt: 1, "foo": "bar"}).Info("baz")
+	is.Equal("INF baz foo=bar phase=rollback\n", out.String())
+}
+
+func TestIsTerminal(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	is.False(isTerminal(&bytes.Buffer{}))
+
+	f, err := os.CreateTemp(t.TempDir(), "")
+	is.NoError(err)
+	defer f.Close()
+	is.False(isTerminal(f), "regular files are not terminals")
+
+	if dev, err := os.OpenFile("/dev/null", os.O_WRONLY, 0); err == nil {
+		defer dev.Close()
+		is.True(isTerminal(dev), "character devices should be detected")
+	}
+}
+
+func TestTerminalPrinter_NoColor(t *testing.T) {
+	dev, err := os.OpenFile("/dev/null", os.O_WRONLY, 0)
+	if err != nil {
+		t.Skip("no character device available")
+	}
+	defer dev.Close()
+
+	t.Setenv("NO_COLOR", "")
+	assert.True(t, NewTerminalPrinter(dev, LevelInfo).(*termPrinter).out.color)
+
+	t.Setenv("NO_COLOR", "1")
+	assert.False(t, NewTerminalPrinter(dev, LevelInfo).(*termPrinter).out.color, "NO_COLOR should disable colors")
+}
@@ -0,0 +1,78 @@
+package runner
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"sync"
+)
+
+// Writer returns an io.WriteCloser that logs each line written to it as a separate message to p at the given LogLevel,
+// allowing the output of subprocesses and third-party libraries to be included in the run's output. Partial lines are
+// buffered until they are completed by a newline or the writer is closed. Trailing carriage returns are removed. Lines
+// are logged verbatim, so they may safely contain formatting verbs.
+func Writer(p Printer, level LogLevel) io.WriteCloser {
+	return &printerWriter{
+		printer: p,
+		level:   level,
+	}
+}
+
+// StdLogger returns a *log.Logger that logs each message to p at the given LogLevel. The logger has no prefix or flags,
+// as the Printer is responsible for formatting its output.
+func StdLogger(p Printer, level LogLevel) *log.Logger {
+	return log.New(Writer(p, level), "", 0)
+}
+
+type printerWriter struct {
+	sync.Mutex
+	printer Printer
+	level   LogLevel
+	buf     []byte
+	closed  bool
+}
+
+func (w *printerWriter) Write(p []byte) (int, error) {
+	w.Lock()
+	defer w.Unlock()
+
+	if w.closed {
+		return 0, io.ErrClosedPipe
+	}
+
+	n := len(p)
+	for {
+		i := bytes.IndexByte(p, '\n')
+		if i < 0 {
+			w.buf = append(w.buf, p...)
+			return n, nil
+		}
+
+		w.buf = append(w.buf, p[:i]...)
+		w.flush()
+		p = p[i+1:]
+	}
+}
+
+// Close logs any buffered partial line. Writes after Close return io.ErrClosedPipe.
+func (w *printerWriter) Close() error {
+	w.Lock()
+	defer w.Unlock()
+
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+
+	if len(w.buf) > 0 {
+		w.flush()
+	}
+	return nil
+}
+
+// flush logs the buffered line. Must be called with w locked.
+func (w *printerWriter) flush() {
+	line := bytes.TrimSuffix(w.buf, []byte{'\r'})
+	w.printer.Log(w.level, "%s", string(line))
+	w.buf = w.buf[:0]
+}
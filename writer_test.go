@@ -0,0 +1,66 @@
+package runner
+
+import (
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriter(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	p, out := getTestPrinter()
+	p.level = LevelInfo
+
+	w := Writer(p.WithPrefix("tool: "), LevelWarn)
+
+	n, err := io.WriteString(w, "100% done\r\nfoo")
+	is.NoError(err)
+	is.Equal(14, n)
+	is.Equal("tool: 100% done\n", out.String(), "partial lines should be buffered")
+
+	fmt.Fprint(w, "bar\n\nbaz")
+	is.Equal("tool: 100% done\ntool: foobar\ntool: \n", out.String())
+
+	is.NoError(w.Close())
+	is.NoError(w.Close())
+	is.Equal("tool: 100% done\ntool: foobar\ntool: \ntool: baz\n", out.String(), "close should flush the partial line")
+
+	_, err = w.Write([]byte("qux\n"))
+	is.Equal(io.ErrClosedPipe, err)
+
+	out.Reset()
+	Writer(p, LevelDebug).Write([]byte("suppressed\n"))
+	is.Empty(out.String(), "lines should be logged at the given level")
+}
+
+func TestStdLogger(t *testing.T) {
+	t.Parallel()
+
+	p, out := getTestPrinter()
+	l := StdLogger(p.WithPrefix("lib: "), LevelInfo)
+
+	l.Printf("%d items", 3)
+	l.Print("multi\nline")
+	assert.Equal(t, "lib: 3 items\nlib: multi\nlib: line\n", out.String())
+}
+
+func TestWriter_Grouped(t *testing.T) {
+	t.Parallel()
+
+	is := assert.New(t)
+	buf := &syncBuffer{}
+	p := NewGroupedPrinter(NewPrinter(buf, LevelInfo))
+
+	cmd := commandFunc(func(ctx Context, p Printer) {
+		w := Writer(p, LevelInfo)
+		io.WriteString(w, "line one\nline two\nline three\n")
+		w.Close()
+	})
+	is.NoError(RunWithPrinter(p, MakeParallel(cmd)))
+	is.Equal("--- runner.commandFunc\nline one\nline two\nline three\n", buf.String(),
+		"captured lines should not share the Writer's buffer")
+}